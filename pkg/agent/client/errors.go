@@ -14,7 +14,13 @@
 
 package client
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/status"
+)
 
 type ImageError string
 
@@ -25,8 +31,56 @@ func (e ImageError) Error() string {
 const (
 	ErrNotFound     ImageError = "not found"
 	ErrUnauthorized ImageError = "unauthorized"
+	ErrInUse        ImageError = "in use"
+	// ErrManifestUnknown is returned when the registry does not have the image.
+	ErrManifestUnknown ImageError = "manifest unknown"
 )
 
 func IsNotFound(err error) bool {
 	return errors.Is(err, ErrNotFound)
 }
+
+func IsInUse(err error) bool {
+	return errors.Is(err, ErrInUse)
+}
+
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+func IsManifestUnknown(err error) bool {
+	return errors.Is(err, ErrManifestUnknown)
+}
+
+// Reason returns a short reason for an error that is suitable to be reported as the
+// reason that an image could not be pulled.
+func Reason(err error) string {
+	var ierr ImageError
+	if errors.As(err, &ierr) {
+		return string(ierr)
+	}
+
+	return status.Convert(err).Message()
+}
+
+// pullError classifies an error returned by the runtime when pulling an image.  The
+// runtimes only return the registry error as part of the message, so the message is
+// matched against the common registry error codes.
+func pullError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := strings.ToLower(status.Convert(err).Message())
+	switch {
+	case strings.Contains(msg, "unauthorized"),
+		strings.Contains(msg, "403 forbidden"),
+		strings.Contains(msg, "denied"):
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case strings.Contains(msg, "manifest unknown"),
+		strings.Contains(msg, "not found"):
+		return fmt.Errorf("%w: %w", ErrManifestUnknown, err)
+	default:
+		return err
+	}
+}
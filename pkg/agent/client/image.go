@@ -106,6 +106,7 @@ func (c *Client) Pull(ctx context.Context, fqn string, auth []*crun.AuthConfig)
 		delete(c.authCache, fqn)
 	}
 
+	var lastErr error
 	for _, a := range auth {
 		log.V(4).Info("attempting to pull image with provided credentials")
 		err := c.pull(ctx, fqn, a)
@@ -114,13 +115,23 @@ func (c *Client) Pull(ctx context.Context, fqn string, auth []*crun.AuthConfig)
 			c.authCache[fqn] = a
 			return nil
 		}
+		lastErr = err
+	}
+
+	log.Error(lastErr, "failed to pull image with provided credentials")
+
+	// Only surface the underlying error if the registry could be reached and reported
+	// that the image does not exist.
+	if IsManifestUnknown(lastErr) {
+		return lastErr
 	}
 
-	log.Error(nil, "failed to pull image with provided credentials")
 	return ErrUnauthorized
 }
 
 func (c *Client) Delete(ctx context.Context, uid, name string) (Info, error) {
+	log := ctrl.LoggerFrom(ctx, "image", name, "uid", uid)
+
 	info, err := c.status(ctx, name)
 	if err != nil {
 		if IsNotFound(err) {
@@ -130,7 +141,26 @@ func (c *Client) Delete(ctx context.Context, uid, name string) (Info, error) {
 		}
 	}
 
-	delete(c.authCache, uid)
+	inUse, err := c.inUse(ctx, info)
+	if err != nil {
+		return Info{}, err
+	}
+
+	if inUse {
+		log.V(3).Info("image is in use by a running container, skipping removal")
+		return info, ErrInUse
+	}
+
+	if err := c.remove(ctx, info.Name); err != nil {
+		return Info{}, err
+	}
+
+	log.V(3).Info("removed image")
+
+	c.Lock()
+	delete(c.authCache, info.Name)
+	c.Unlock()
+
 	return info, nil
 }
 
@@ -150,6 +180,7 @@ func (c *Client) List(ctx context.Context) ([]string, error) {
 	images := make([]string, 0)
 	for _, img := range resp.GetImages() {
 		images = append(images, img.GetRepoTags()...)
+		images = append(images, img.GetRepoDigests()...)
 	}
 
 	return images, nil
@@ -166,14 +197,65 @@ func (c *Client) pull(ctx context.Context, name string, auth *crun.AuthConfig) (
 		Auth: auth,
 	})
 
+	return pullError(err)
+}
+
+func (c *Client) remove(ctx context.Context, name string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	_, err := c.isc.RemoveImage(ctx, &crun.RemoveImageRequest{
+		Image: &crun.ImageSpec{
+			Image: name,
+		},
+	})
+
 	return err
 }
 
+// inUse returns true if any running container on the node references the image either
+// by its ID or by one of its tags.
+func (c *Client) inUse(ctx context.Context, info Info) (bool, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	resp, err := c.rsc.ListContainers(ctx, &crun.ListContainersRequest{
+		Filter: &crun.ContainerFilter{
+			State: &crun.ContainerStateValue{
+				State: crun.ContainerState_CONTAINER_RUNNING,
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	refs := map[string]bool{
+		info.ID:   true,
+		info.Name: true,
+	}
+	for _, tag := range info.Tags {
+		refs[tag] = true
+	}
+
+	for _, ctr := range resp.GetContainers() {
+		if refs[ctr.GetImageRef()] || refs[ctr.GetImageId()] || refs[ctr.GetImage().GetImage()] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (c *Client) status(ctx context.Context, name string) (Info, error) {
 	c.Lock()
 	defer c.Unlock()
 
-	fqn := iutil.GetImageQualifiedName(iutil.DefaultSearchRegistry, name)
+	ref, err := iutil.ParseReference(name)
+	if err != nil {
+		return Info{}, err
+	}
+	fqn := ref.String()
 
 	resp, err := c.isc.ImageStatus(ctx, &crun.ImageStatusRequest{
 		Image: &crun.ImageSpec{
@@ -189,9 +271,11 @@ func (c *Client) status(ctx context.Context, name string) (Info, error) {
 	}
 
 	return Info{
-		ID:   resp.GetImage().GetId(),
-		Name: fqn,
-		Tags: resp.GetImage().GetRepoTags(),
+		ID:      resp.GetImage().GetId(),
+		Name:    fqn,
+		Tags:    resp.GetImage().GetRepoTags(),
+		Digests: resp.GetImage().GetRepoDigests(),
+		Size:    resp.GetImage().GetSize(),
 	}, nil
 }
 
@@ -0,0 +1,257 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+
+	iutil "ctx.sh/coral/pkg/util"
+	crun "k8s.io/cri-api/pkg/apis/runtime/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// PinLabel is set on all sandboxes and containers that are used to pin images.
+	PinLabel = "coral.ctx.sh/pin"
+	// PinOwnerLabel identifies the owner (imagesync uid) of the pin sandbox.
+	PinOwnerLabel = "coral.ctx.sh/pin-owner"
+	// PinImageAnnotation records the image that a pin container is holding.
+	PinImageAnnotation = "coral.ctx.sh/pin-image"
+	// PinImageIDAnnotation records the id of the image when the pin container was created.
+	PinImageIDAnnotation = "coral.ctx.sh/pin-image-id"
+	// PinNamespace is the namespace reported to the runtime for the pin sandboxes.
+	PinNamespace = "coral-system"
+	// PinCommand is the command set on the pin containers.  The containers are never started
+	// so it does not need to exist in the image, but the runtime requires one to be set.
+	PinCommand = "/coral-pin"
+)
+
+// Pin holds a pod sandbox for the owner with a created, but never started, container for each
+// of the images.  The kubelet image garbage collection considers any image referenced by a
+// container to be in use, so the images will not be evicted while the pin exists.  Pin is
+// idempotent; missing containers are recreated, containers for images that are no longer
+// in the list are removed and containers holding an image that the tag no longer points to
+// are replaced.
+func (c *Client) Pin(ctx context.Context, owner string, images []string) error {
+	log := ctrl.LoggerFrom(ctx, "owner", owner)
+
+	c.Lock()
+	defer c.Unlock()
+
+	config := pinSandboxConfig(owner)
+
+	sandboxID, err := c.pinSandbox(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	if sandboxID == "" {
+		resp, err := c.rsc.RunPodSandbox(ctx, &crun.RunPodSandboxRequest{Config: config})
+		if err != nil {
+			return err
+		}
+		sandboxID = resp.GetPodSandboxId()
+		log.V(3).Info("created pin sandbox", "sandbox", sandboxID)
+	}
+
+	resp, err := c.rsc.ListContainers(ctx, &crun.ListContainersRequest{
+		Filter: &crun.ContainerFilter{
+			PodSandboxId: sandboxID,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The id of each image is compared with the id that the container was created with so
+	// that the previous image is released when a tag moves to a new digest.
+	wanted := make(map[string]string, len(images))
+	for _, image := range images {
+		id, err := c.imageID(ctx, image)
+		if err != nil {
+			return err
+		}
+		wanted[image] = id
+	}
+
+	existing := make(map[string]bool)
+	for _, ctr := range resp.GetContainers() {
+		image := ctr.GetAnnotations()[PinImageAnnotation]
+		if id, ok := wanted[image]; ok && !existing[image] {
+			// The image is kept pinned if its id can not be determined.
+			if id == "" || ctr.GetAnnotations()[PinImageIDAnnotation] == id {
+				existing[image] = true
+				continue
+			}
+		}
+
+		if _, err := c.rsc.RemoveContainer(ctx, &crun.RemoveContainerRequest{ContainerId: ctr.GetId()}); err != nil {
+			return err
+		}
+		log.V(3).Info("removed pin container", "image", image)
+	}
+
+	for _, image := range images {
+		if existing[image] {
+			continue
+		}
+
+		_, err := c.rsc.CreateContainer(ctx, &crun.CreateContainerRequest{
+			PodSandboxId:  sandboxID,
+			Config:        pinContainerConfig(owner, image, wanted[image]),
+			SandboxConfig: config,
+		})
+		if err != nil {
+			return err
+		}
+		log.V(3).Info("created pin container", "image", image)
+	}
+
+	return nil
+}
+
+// Unpin removes the pin sandbox and all of its containers for the owner.
+func (c *Client) Unpin(ctx context.Context, owner string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	sandboxID, err := c.pinSandbox(ctx, owner)
+	if err != nil || sandboxID == "" {
+		return err
+	}
+
+	if _, err := c.rsc.StopPodSandbox(ctx, &crun.StopPodSandboxRequest{PodSandboxId: sandboxID}); err != nil {
+		return err
+	}
+
+	if _, err := c.rsc.RemovePodSandbox(ctx, &crun.RemovePodSandboxRequest{PodSandboxId: sandboxID}); err != nil {
+		return err
+	}
+
+	ctrl.LoggerFrom(ctx, "owner", owner).V(3).Info("removed pin sandbox", "sandbox", sandboxID)
+
+	return nil
+}
+
+// Pinned returns the images that are currently held by pin containers on the node.
+func (c *Client) Pinned(ctx context.Context) ([]string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	resp, err := c.rsc.ListContainers(ctx, &crun.ListContainersRequest{
+		Filter: &crun.ContainerFilter{
+			LabelSelector: map[string]string{PinLabel: "true"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	images := make([]string, 0)
+	for _, ctr := range resp.GetContainers() {
+		image := ctr.GetAnnotations()[PinImageAnnotation]
+		if image != "" && !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+
+	return images, nil
+}
+
+// pinSandbox returns the id of the ready pin sandbox for the owner or an empty string if one
+// does not exist.  The caller must hold the lock.
+func (c *Client) pinSandbox(ctx context.Context, owner string) (string, error) {
+	resp, err := c.rsc.ListPodSandbox(ctx, &crun.ListPodSandboxRequest{
+		Filter: &crun.PodSandboxFilter{
+			State: &crun.PodSandboxStateValue{
+				State: crun.PodSandboxState_SANDBOX_READY,
+			},
+			LabelSelector: map[string]string{
+				PinLabel:      "true",
+				PinOwnerLabel: owner,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, sb := range resp.GetItems() {
+		return sb.GetId(), nil
+	}
+
+	return "", nil
+}
+
+// imageID returns the id of the image on the node or an empty string if the image is not
+// present.  The caller must hold the lock.
+func (c *Client) imageID(ctx context.Context, image string) (string, error) {
+	resp, err := c.isc.ImageStatus(ctx, &crun.ImageStatusRequest{
+		Image: &crun.ImageSpec{
+			Image: image,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.GetImage().GetId(), nil
+}
+
+func pinSandboxConfig(owner string) *crun.PodSandboxConfig {
+	return &crun.PodSandboxConfig{
+		Metadata: &crun.PodSandboxMetadata{
+			Name:      "coral-pin-" + owner,
+			Uid:       owner,
+			Namespace: PinNamespace,
+		},
+		Labels: map[string]string{
+			PinLabel:      "true",
+			PinOwnerLabel: owner,
+		},
+		// The sandbox shares the network of the node so that the runtime does not set up
+		// pod networking and allocate an address for a sandbox that never runs anything.
+		Linux: &crun.LinuxPodSandboxConfig{
+			SecurityContext: &crun.LinuxSandboxSecurityContext{
+				NamespaceOptions: &crun.NamespaceOption{
+					Network: crun.NamespaceMode_NODE,
+				},
+			},
+		},
+	}
+}
+
+func pinContainerConfig(owner, image, id string) *crun.ContainerConfig {
+	return &crun.ContainerConfig{
+		Metadata: &crun.ContainerMetadata{
+			Name: "pin-" + iutil.GetImageLabelValue(image),
+		},
+		Image: &crun.ImageSpec{
+			Image: image,
+		},
+		Command: []string{PinCommand},
+		Labels: map[string]string{
+			PinLabel:      "true",
+			PinOwnerLabel: owner,
+		},
+		Annotations: map[string]string{
+			PinImageAnnotation:   image,
+			PinImageIDAnnotation: id,
+		},
+		Linux: &crun.LinuxContainerConfig{},
+	}
+}
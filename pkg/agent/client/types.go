@@ -27,8 +27,14 @@ type ImageClient interface {
 	Delete(ctx context.Context, uid, name string) (Info, error)
 	// Status returns the status of an image.
 	Status(ctx context.Context, name string) (Info, error)
-	// List lists all images.
+	// List lists all images by their repo tags and repo digests.
 	List(ctx context.Context) ([]string, error)
+	// Pin protects the images from the kubelet image garbage collection on behalf of the owner.
+	Pin(ctx context.Context, owner string, images []string) error
+	// Unpin releases all images pinned on behalf of the owner.
+	Unpin(ctx context.Context, owner string) error
+	// Pinned lists all images that are currently pinned.
+	Pinned(ctx context.Context) ([]string, error)
 }
 
 type Info struct {
@@ -38,6 +44,10 @@ type Info struct {
 	Name string
 	// Tags are the tags associated with the image.
 	Tags []string
+	// Digests are the repository digests associated with the image.
+	Digests []string
+	// Size is the size of the image in bytes.
+	Size uint64
 }
 
 type ImageServiceClient interface {
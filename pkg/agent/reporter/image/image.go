@@ -30,6 +30,8 @@ import (
 	"ctx.sh/coral/pkg/agent/client"
 	coralv1beta1 "ctx.sh/coral/pkg/gen/coral/v1beta1"
 	"ctx.sh/coral/pkg/gen/coral/v1beta1/coralv1beta1connect"
+	"ctx.sh/coral/pkg/store"
+	"ctx.sh/coral/pkg/util"
 	"golang.org/x/net/http2"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -52,6 +54,8 @@ var (
 type Options struct {
 	ImageClient        client.ImageClient
 	NodeName           string
+	Ledger             *store.Ledger
+	Pulls              *store.Pulls
 	PollInterval       time.Duration
 	Host               string
 	CertName           string
@@ -65,6 +69,8 @@ type Options struct {
 type Image struct {
 	ImageClient  client.ImageClient
 	NodeName     string
+	Ledger       *store.Ledger
+	Pulls        *store.Pulls
 	PollInterval time.Duration
 	Options      Options
 }
@@ -73,6 +79,8 @@ func SetupWithManager(mgr ctrl.Manager, opts Options) error {
 	img := &Image{
 		ImageClient:  opts.ImageClient,
 		NodeName:     opts.NodeName,
+		Ledger:       opts.Ledger,
+		Pulls:        opts.Pulls,
 		PollInterval: opts.PollInterval,
 		Options:      opts,
 	}
@@ -136,18 +144,27 @@ func (i *Image) Start(ctx context.Context) error {
 
 func (i *Image) run(ctx context.Context, hc *http.Client) error {
 	conn := coralv1beta1connect.NewCoralServiceClient(hc, i.Options.Host)
-	images, err := i.ImageClient.List(ctx)
+	images, err := i.images(ctx)
+	if err != nil {
+		return err
+	}
+
+	pinned, err := i.ImageClient.Pinned(ctx)
 	if err != nil {
 		return err
 	}
 
+	states := i.states(ctx, images)
+
 	count := 0
 	var resp *connect.Response[coralv1beta1.ReporterResponse]
 	err = wait.ExponentialBackoffWithContext(ctx, DefaultBackoff, func(context.Context) (bool, error) {
 		count++
 		if resp, err = conn.Reporter(ctx, connect.NewRequest(&coralv1beta1.ReporterRequest{
-			Image: images,
-			Node:  i.NodeName,
+			Image:  images,
+			Node:   i.NodeName,
+			Pinned: pinned,
+			Images: states,
 		})); err != nil {
 			if count < DefaultBackoff.Steps {
 				return false, nil
@@ -167,3 +184,100 @@ func (i *Image) run(ctx context.Context, hc *http.Client) error {
 
 	return nil
 }
+
+// images returns the images present on the node.  When a ledger is available only the images
+// that are managed by coral, along with the digests of their repositories, are reported.
+func (i *Image) images(ctx context.Context) ([]string, error) {
+	images, err := i.ImageClient.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.Ledger == nil {
+		return images, nil
+	}
+
+	repositories := make(map[string]bool)
+	for _, img := range i.Ledger.Images() {
+		if ref, err := util.ParseReference(img); err == nil {
+			repositories[ref.Repository()] = true
+		}
+	}
+
+	managed := make([]string, 0, len(images))
+	for _, img := range images {
+		if i.Ledger.IsReferenced(img) {
+			managed = append(managed, img)
+			continue
+		}
+
+		if ref, err := util.ParseReference(img); err == nil && ref.IsDigested() && repositories[ref.Repository()] {
+			managed = append(managed, img)
+		}
+	}
+
+	return managed, nil
+}
+
+// states returns the state of each of the images managed by coral on the node.  Images that
+// are not present and have not failed are reported as pulling until they become available.
+func (i *Image) states(ctx context.Context, images []string) []*coralv1beta1.ImageStatus {
+	if i.Ledger == nil {
+		return nil
+	}
+
+	present := make(map[string]bool, len(images))
+	for _, img := range images {
+		present[img] = true
+	}
+
+	managed := i.Ledger.Images()
+	states := make([]*coralv1beta1.ImageStatus, 0, len(managed))
+	for _, img := range managed {
+		state := &coralv1beta1.ImageStatus{
+			Image: img,
+			State: coralv1beta1.ImageState_IMAGE_STATE_PULLING,
+		}
+
+		pull, pulling := i.pull(img)
+		switch {
+		case pulling && pull.Failed:
+			state.State = coralv1beta1.ImageState_IMAGE_STATE_FAILED
+			state.Reason = pull.Reason
+		case !pulling && present[img]:
+			state.State = coralv1beta1.ImageState_IMAGE_STATE_AVAILABLE
+			if info, err := i.ImageClient.Status(ctx, img); err == nil {
+				state.Digest = digest(img, info.Digests)
+				state.Size = info.Size
+			}
+		}
+
+		states = append(states, state)
+	}
+
+	return states
+}
+
+func (i *Image) pull(image string) (store.Pull, bool) {
+	if i.Pulls == nil {
+		return store.Pull{}, false
+	}
+
+	return i.Pulls.Get(image)
+}
+
+// digest returns the digest of the repository digest that matches the repository of the image.
+func digest(image string, digests []string) string {
+	ref, err := util.ParseReference(image)
+	if err != nil {
+		return ""
+	}
+
+	for _, d := range digests {
+		if dref, err := util.ParseReference(d); err == nil && dref.Repository() == ref.Repository() {
+			return dref.Digest()
+		}
+	}
+
+	return ""
+}
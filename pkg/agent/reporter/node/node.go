@@ -0,0 +1,153 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"ctx.sh/coral/pkg/agent/client"
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/store"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Options struct {
+	ImageClient  client.ImageClient
+	NodeName     string
+	Ledger       *store.Ledger
+	Pulls        *store.Pulls
+	PollInterval time.Duration
+}
+
+// Node maintains the image availability labels on the node that the agent runs on.  Each of
+// the images managed by coral that is present on the node is labeled with
+// imagesync.coral.ctx.sh/<hash>=available so that workloads can target the nodes with plain
+// label selectors.  Labels for images that are no longer available are removed.
+type Node struct {
+	ctrlclient.Client
+	ImageClient  client.ImageClient
+	NodeName     string
+	Ledger       *store.Ledger
+	Pulls        *store.Pulls
+	PollInterval time.Duration
+}
+
+func SetupWithManager(mgr ctrl.Manager, opts Options) error {
+	n := &Node{
+		Client:       mgr.GetClient(),
+		ImageClient:  opts.ImageClient,
+		NodeName:     opts.NodeName,
+		Ledger:       opts.Ledger,
+		Pulls:        opts.Pulls,
+		PollInterval: opts.PollInterval,
+	}
+
+	return mgr.Add(n)
+}
+
+// NeedLeaderElection returns false as every agent labels its own node.
+func (n *Node) NeedLeaderElection() bool {
+	return false
+}
+
+func (n *Node) Start(ctx context.Context) error {
+	ticker := time.NewTicker(n.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := n.run(ctx); err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "Failed to update the image labels on the node")
+			}
+		}
+	}
+}
+
+func (n *Node) run(ctx context.Context) error {
+	desired, err := n.labels(ctx)
+	if err != nil {
+		return err
+	}
+
+	node := new(corev1.Node)
+	if err := n.Get(ctx, ctrlclient.ObjectKey{Name: n.NodeName}, node); err != nil {
+		return err
+	}
+
+	current := node.GetLabels()
+	updated := make(map[string]string, len(current)+len(desired))
+	for k, v := range current {
+		if util.IsImageLabelKey(k) && desired[k] == "" {
+			continue
+		}
+		updated[k] = v
+	}
+	maps.Copy(updated, desired)
+
+	if maps.Equal(current, updated) {
+		return nil
+	}
+
+	patch := ctrlclient.MergeFrom(node.DeepCopy())
+	node.SetLabels(updated)
+
+	return n.Patch(ctx, node, patch)
+}
+
+// labels returns the availability labels for the images managed by coral that are present on
+// the node and are not being pulled.
+func (n *Node) labels(ctx context.Context) (map[string]string, error) {
+	labels := make(map[string]string)
+	if n.Ledger == nil {
+		return labels, nil
+	}
+
+	images, err := n.ImageClient.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]bool, len(images))
+	for _, img := range images {
+		present[img] = true
+	}
+
+	for _, img := range n.Ledger.Images() {
+		if !present[img] || n.pulling(img) {
+			continue
+		}
+
+		labels[util.GetImageLabelKey(img)] = coralv1beta1.ImageAvailableLabelValue
+	}
+
+	return labels, nil
+}
+
+func (n *Node) pulling(image string) bool {
+	if n.Pulls == nil {
+		return false
+	}
+
+	_, pulling := n.Pulls.Get(image)
+	return pulling
+}
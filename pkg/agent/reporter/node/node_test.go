@@ -0,0 +1,83 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/mock"
+	"ctx.sh/coral/pkg/store"
+	"ctx.sh/coral/pkg/util"
+	"github.com/stretchr/testify/assert"
+	smock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNode_run(t *testing.T) {
+	ctx := context.Background()
+
+	c := mock.NewClient().WithFixtureDirectory(filepath.Join("..", "..", "..", "..", "fixtures"))
+	c.ApplyFixtureOrDie("nodes.yaml")
+
+	golang := "docker.io/library/golang:latest"
+	nginx := "docker.io/library/nginx:latest"
+	redis := "docker.io/library/redis:latest"
+
+	// Seed a stale label for an image that is no longer managed along with a label that is
+	// not owned by coral.
+	var node corev1.Node
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Name: "node1"}, &node))
+	node.Labels = map[string]string{
+		"role":                       "app",
+		util.GetImageLabelKey(redis): coralv1beta1.ImageAvailableLabelValue,
+	}
+	require.NoError(t, c.Update(ctx, &node))
+
+	ledger := store.NewLedger()
+	ledger.Set("uid", []string{golang, nginx})
+
+	pulls := store.NewPulls()
+	pulls.Start(nginx)
+
+	ic := mock.NewMockImageClient(t)
+	ic.EXPECT().List(smock.Anything).Return([]string{golang, nginx, redis}, nil)
+
+	n := &Node{
+		Client:      c,
+		ImageClient: ic,
+		NodeName:    "node1",
+		Ledger:      ledger,
+		Pulls:       pulls,
+	}
+
+	require.NoError(t, n.run(ctx))
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Name: "node1"}, &node))
+	assert.Equal(t, map[string]string{
+		"role":                        "app",
+		util.GetImageLabelKey(golang): coralv1beta1.ImageAvailableLabelValue,
+	}, node.Labels)
+
+	// The label is added once the pull completes.
+	pulls.Done(nginx)
+
+	require.NoError(t, n.run(ctx))
+	require.NoError(t, c.Get(ctx, ctrlclient.ObjectKey{Name: "node1"}, &node))
+	assert.Equal(t, coralv1beta1.ImageAvailableLabelValue, node.Labels[util.GetImageLabelKey("nginx")])
+}
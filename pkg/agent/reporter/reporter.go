@@ -21,6 +21,8 @@ import (
 
 	"ctx.sh/coral/pkg/agent/client"
 	"ctx.sh/coral/pkg/agent/reporter/image"
+	"ctx.sh/coral/pkg/agent/reporter/node"
+	"ctx.sh/coral/pkg/store"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -35,6 +37,8 @@ const (
 type Options struct {
 	ContainerAddr      string
 	NodeName           string
+	Ledger             *store.Ledger
+	Pulls              *store.Pulls
 	Host               string
 	Port               int
 	CertDir            string
@@ -54,6 +58,8 @@ func SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts *Options) erro
 	if err := image.SetupWithManager(mgr, image.Options{
 		ImageClient:  imageClient,
 		NodeName:     opts.NodeName,
+		Ledger:       opts.Ledger,
+		Pulls:        opts.Pulls,
 		PollInterval: DefaultPollInterval,
 		Host:         opts.Host,
 		CertName:     opts.CertName,
@@ -63,5 +69,16 @@ func SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts *Options) erro
 	}); err != nil {
 		return err
 	}
+
+	if err := node.SetupWithManager(mgr, node.Options{
+		ImageClient:  imageClient,
+		NodeName:     opts.NodeName,
+		Ledger:       opts.Ledger,
+		Pulls:        opts.Pulls,
+		PollInterval: DefaultPollInterval,
+	}); err != nil {
+		return err
+	}
+
 	return nil
 }
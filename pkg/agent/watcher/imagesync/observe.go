@@ -21,14 +21,15 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type ObservedState struct {
-	ImageSync   *coralv1beta1.ImageSync
+	ImageSync   coralv1beta1.ImageSyncObject
 	PullSecrets []corev1.Secret
 	Node        *corev1.Node
 	ObserveTime time.Time
@@ -75,7 +76,7 @@ func (o *StateObserver) observe(ctx context.Context, observed *ObservedState) er
 	observed.ImageSync = observedImageSync
 
 	// TODO: not sure I like this here.
-	matches, err := o.nodeMatches(observedNode, observedImageSync.Spec.NodeSelector)
+	matches, err := util.NodeMatches(observedNode, observedImageSync)
 	if err != nil {
 		return err
 	}
@@ -93,8 +94,8 @@ func (o *StateObserver) observe(ctx context.Context, observed *ObservedState) er
 	return nil
 }
 
-func (o *StateObserver) observerImageSync(ctx context.Context) (*coralv1beta1.ImageSync, error) {
-	observedImageSync := new(coralv1beta1.ImageSync)
+func (o *StateObserver) observerImageSync(ctx context.Context) (coralv1beta1.ImageSyncObject, error) {
+	observedImageSync := util.NewImageSyncObject(o.Request.Cluster)
 	err := o.Client.Get(ctx, o.Request.NamespacedName, observedImageSync)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -103,24 +104,21 @@ func (o *StateObserver) observerImageSync(ctx context.Context) (*coralv1beta1.Im
 		return nil, err
 	}
 
-	return observedImageSync.DeepCopy(), nil
+	return observedImageSync.DeepCopyObject().(coralv1beta1.ImageSyncObject), nil
 }
 
 func (o *StateObserver) observePullSecrets(ctx context.Context, observed *ObservedState) ([]corev1.Secret, error) {
 	observedPullSecrets := make([]corev1.Secret, 0)
 	missingPullSecrets := make([]string, 0)
 
-	for _, pullSecret := range observed.ImageSync.Spec.ImagePullSecrets {
+	for _, pullSecret := range observed.ImageSync.GetPullSecrets() {
 		secret := new(corev1.Secret)
-		err := o.Client.Get(ctx, client.ObjectKey{
-			Name:      pullSecret.Name,
-			Namespace: observed.ImageSync.Namespace,
-		}, secret)
+		err := o.Client.Get(ctx, pullSecret, secret)
 		if err != nil {
 			if client.IgnoreNotFound(err) != nil {
 				return nil, err
 			}
-			missingPullSecrets = append(missingPullSecrets, pullSecret.Name)
+			missingPullSecrets = append(missingPullSecrets, pullSecret.String())
 		}
 
 		observedPullSecrets = append(observedPullSecrets, *secret.DeepCopy())
@@ -139,6 +137,35 @@ func (o *StateObserver) observePullSecrets(ctx context.Context, observed *Observ
 	return observedPullSecrets, nil
 }
 
+// observeReferences returns the fully qualified images referenced by the imagesyncs that
+// match the node, excluding the imagesync with the given uid and any that are being deleted.
+func (o *StateObserver) observeReferences(ctx context.Context, node *corev1.Node, exclude types.UID) (map[string]bool, error) {
+	items, err := util.ListImageSyncs(ctx, o.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool)
+	for _, item := range items {
+		if item.GetUID() == exclude || !item.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		matches, err := util.NodeMatches(node, item)
+		if err != nil || !matches {
+			continue
+		}
+
+		for _, img := range item.GetImages() {
+			if ref, err := util.ParseReference(img); err == nil {
+				referenced[ref.String()] = true
+			}
+		}
+	}
+
+	return referenced, nil
+}
+
 func (o *StateObserver) observeNode(ctx context.Context) (*corev1.Node, error) {
 	node := new(corev1.Node)
 	err := o.Client.Get(ctx, client.ObjectKey{Name: o.NodeName}, node)
@@ -170,16 +197,3 @@ func (o *StateObserver) nodeIsReady(node *corev1.Node) bool {
 
 	return conditionReady && conditionNoDiskPressure && conditionNoPIDPressure
 }
-
-func (o *StateObserver) nodeMatches(node *corev1.Node, selectors []coralv1beta1.NodeSelector) (bool, error) {
-	ls := labels.NewSelector()
-	for _, v := range selectors {
-		req, err := labels.NewRequirement(v.Key, v.Operator, v.Values)
-		if err != nil {
-			return false, err
-		}
-		ls = ls.Add(*req)
-	}
-
-	return ls.Matches(labels.Set(node.GetLabels())), nil
-}
@@ -179,6 +179,51 @@ func (s *ObserveTestSuite) TestStateObserve_observe_node_matched() {
 	s.Empty(observed.PullSecrets)
 }
 
+func (s *ObserveTestSuite) TestStateObserve_observe_node_affinity() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie(
+		"example_with_affinity.yaml",
+	)
+
+	var node corev1.Node
+	err := s.client.Get(ctx, client.ObjectKey{Name: "node1"}, &node)
+	s.NoError(err)
+
+	observer := &StateObserver{
+		Client:   s.client,
+		NodeName: "node1", // Matched by the metadata.name field selector term
+		Request: Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: "default",
+				Name:      "example",
+			},
+		},
+	}
+
+	err = observer.observe(ctx, NewObservedState())
+	s.NoError(err)
+
+	node.Spec.Taints = []corev1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+	}
+	err = s.client.Update(ctx, &node)
+	s.NoError(err)
+
+	err = observer.observe(ctx, NewObservedState())
+	s.NoError(err)
+
+	node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+		Key: "maintenance", Effect: corev1.TaintEffectNoExecute,
+	})
+	err = s.client.Update(ctx, &node)
+	s.NoError(err)
+
+	err = observer.observe(ctx, NewObservedState())
+	s.ErrorIs(err, ErrNodeMatch)
+}
+
 func (s *ObserveTestSuite) TestStateObserve_observe_without_imagesync() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -233,6 +278,36 @@ func (s *ObserveTestSuite) TestStateObserve_observe_with_pullsecrets() {
 	s.Equal("fake-credentials", observed.PullSecrets[0].GetName())
 }
 
+func (s *ObserveTestSuite) TestStateObserve_observe_cluster() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie(
+		"clusterimagesync.yaml",
+	)
+
+	observed := NewObservedState()
+	observer := &StateObserver{
+		Client:   s.client,
+		NodeName: "node1",
+		Request: Request{
+			NamespacedName: types.NamespacedName{
+				Name: "example-cluster",
+			},
+			Cluster: true,
+		},
+	}
+
+	err := observer.observe(ctx, observed)
+
+	s.NoError(err)
+	s.NotNil(observed.ImageSync)
+	s.Equal("example-cluster", observed.ImageSync.GetName())
+	s.Len(observed.PullSecrets, 1)
+	s.Equal("fake-credentials", observed.PullSecrets[0].GetName())
+	s.Equal("platform", observed.PullSecrets[0].GetNamespace())
+}
+
 func (s *ObserveTestSuite) TestStateObserve_observe_with_missing_pullsecrets() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -0,0 +1,90 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagesync
+
+import (
+	"context"
+	"time"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// Resync periodically enqueues every imagesync so that the watcher can compare the images on
+// the node with the images that are expected and re-pull anything that has been removed out
+// from under us, e.g. by the kubelet image garbage collection.
+type Resync struct {
+	interval time.Duration
+	jitter   float64
+	events   chan event.TypedGenericEvent[coralv1beta1.ImageSyncObject]
+	client.Client
+}
+
+func NewResync(c client.Client, interval time.Duration, jitter float64) *Resync {
+	return &Resync{
+		interval: interval,
+		jitter:   jitter,
+		events:   make(chan event.TypedGenericEvent[coralv1beta1.ImageSyncObject]),
+		Client:   c,
+	}
+}
+
+// Events returns the channel that the resync events are sent on.
+func (r *Resync) Events() <-chan event.TypedGenericEvent[coralv1beta1.ImageSyncObject] {
+	return r.events
+}
+
+// NeedLeaderElection returns false as every agent is responsible for its own node.
+func (r *Resync) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Resync) Start(ctx context.Context) error {
+	// Spread the first resync out so that a whole node pool that was started at the same
+	// time does not hit the registries all at once.
+	delay := wait.Jitter(r.interval, r.jitter) - r.interval
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-time.After(delay):
+	}
+
+	wait.JitterUntilWithContext(ctx, r.run, r.interval, r.jitter, false)
+	return nil
+}
+
+func (r *Resync) run(ctx context.Context) {
+	log := ctrl.LoggerFrom(ctx)
+
+	items, err := util.ListImageSyncs(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "unable to list imagesyncs for resync")
+		return
+	}
+
+	log.V(3).Info("resyncing imagesyncs", "count", len(items))
+
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+			return
+		case r.events <- event.TypedGenericEvent[coralv1beta1.ImageSyncObject]{Object: item}:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagesync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ctx.sh/coral/pkg/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+type ResyncTestSuite struct {
+	client *mock.Client
+	suite.Suite
+}
+
+func (s *ResyncTestSuite) SetupTest() {
+	logger := zap.New(zap.UseDevMode(true), zap.Level(zapcore.Level(-8)))
+	log.SetLogger(logger)
+
+	s.client = mock.NewClient().
+		WithLogger(logger).
+		WithFixtureDirectory(filepath.Join("..", "..", "..", "..", "fixtures"))
+}
+
+func (s *ResyncTestSuite) TearDownTest() {
+	s.client.Reset()
+}
+
+func TestResyncTestSuite(t *testing.T) {
+	suite.Run(t, new(ResyncTestSuite))
+}
+
+func (s *ResyncTestSuite) TestRun() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher.yaml")
+
+	r := NewResync(s.client, time.Minute, 0.5)
+	go r.run(ctx)
+
+	select {
+	case e := <-r.Events():
+		s.Equal("example", e.Object.GetName())
+		s.Equal("b7f01748-4d55-4bc3-939a-a458c19ca533", string(e.Object.GetUID()))
+	case <-ctx.Done():
+		s.Fail("timed out waiting for resync event")
+	}
+}
@@ -17,12 +17,15 @@ package imagesync
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	imageClient "ctx.sh/coral/pkg/agent/client"
 	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
 	"ctx.sh/coral/pkg/limiter"
+	"ctx.sh/coral/pkg/store"
 	"ctx.sh/coral/pkg/util"
+	utilauth "ctx.sh/coral/pkg/util/auth"
 	"golang.org/x/sync/errgroup"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,67 +46,118 @@ const (
 type Options struct {
 	Limiter                  *limiter.Limiter
 	ImageClient              imageClient.ImageClient
+	Ledger                   *store.Ledger
+	Pulls                    *store.Pulls
 	MaxConcurrentPullers     int
 	MaxConcurrentReconcilers int
 	NodeName                 string
+	// ResyncInterval is the interval at which all imagesyncs are reconciled to repair any
+	// drift on the node.  A zero value disables the resync.
+	ResyncInterval time.Duration
+	// ResyncJitter is the jitter factor applied to the resync interval.
+	ResyncJitter float64
 }
 
 type Request struct {
 	types.NamespacedName
+	// UID is the uid of the imagesync that triggered the request.  It is used to release the
+	// images that were pulled on behalf of the imagesync once it has been removed.
+	UID types.UID
+	// Cluster is true when the request is for a clusterimagesync.
+	Cluster bool
 }
 
 type Watcher struct {
 	processor   *limiter.Limiter
 	nodeName    string
 	imageClient imageClient.ImageClient
+	ledger      *store.Ledger
+	pulls       *store.Pulls
+	restored    bool
 	client.Client
+
+	sync.Mutex
 }
 
 func SetupWithManager(mgr ctrl.Manager, opts *Options) error {
+	ledger := opts.Ledger
+	if ledger == nil {
+		ledger = store.NewLedger()
+	}
+
+	pulls := opts.Pulls
+	if pulls == nil {
+		pulls = store.NewPulls()
+	}
+
 	w := &Watcher{
 		processor:   opts.Limiter,
 		nodeName:    opts.NodeName,
 		imageClient: opts.ImageClient,
+		ledger:      ledger,
+		pulls:       pulls,
 		Client:      mgr.GetClient(),
 	}
 
-	h := handler.TypedFuncs[*coralv1beta1.ImageSync, Request]{
-		CreateFunc: func(ctx context.Context, e event.TypedCreateEvent[*coralv1beta1.ImageSync], w workqueue.TypedRateLimitingInterface[Request]) {
+	b := builder.TypedControllerManagedBy[Request](mgr).
+		WatchesRawSource(source.TypedKind(
+			mgr.GetCache(),
+			&coralv1beta1.ImageSync{},
+			newHandler[*coralv1beta1.ImageSync]()),
+		).
+		WatchesRawSource(source.TypedKind(
+			mgr.GetCache(),
+			&coralv1beta1.ClusterImageSync{},
+			newHandler[*coralv1beta1.ClusterImageSync]()),
+		).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Named("imagesync-watcher")
+
+	if opts.ResyncInterval > 0 {
+		resync := NewResync(mgr.GetClient(), opts.ResyncInterval, opts.ResyncJitter)
+		if err := mgr.Add(resync); err != nil {
+			return err
+		}
+
+		b = b.WatchesRawSource(source.TypedChannel(resync.Events(), newHandler[coralv1beta1.ImageSyncObject]()))
+	}
+
+	return b.Complete(w)
+}
+
+// newHandler returns the event handler for imagesyncs and clusterimagesyncs.
+func newHandler[T coralv1beta1.ImageSyncObject]() handler.TypedFuncs[T, Request] {
+	return handler.TypedFuncs[T, Request]{
+		CreateFunc: func(ctx context.Context, e event.TypedCreateEvent[T], w workqueue.TypedRateLimitingInterface[Request]) {
 			// Do nothing.  We handle when the finalizer is added in the update.
 		},
-		UpdateFunc: func(ctx context.Context, e event.TypedUpdateEvent[*coralv1beta1.ImageSync], w workqueue.TypedRateLimitingInterface[Request]) {
-			w.Add(Request{
-				NamespacedName: types.NamespacedName{
-					Name:      e.ObjectNew.GetName(),
-					Namespace: e.ObjectNew.GetNamespace(),
-				},
-			})
+		UpdateFunc: func(ctx context.Context, e event.TypedUpdateEvent[T], w workqueue.TypedRateLimitingInterface[Request]) {
+			w.Add(newRequest(e.ObjectNew))
 		},
-		DeleteFunc: func(ctx context.Context, e event.TypedDeleteEvent[*coralv1beta1.ImageSync], w workqueue.TypedRateLimitingInterface[Request]) {
-			w.Add(Request{
-				NamespacedName: types.NamespacedName{
-					Name:      e.Object.GetName(),
-					Namespace: e.Object.GetNamespace(),
-				},
-			})
+		DeleteFunc: func(ctx context.Context, e event.TypedDeleteEvent[T], w workqueue.TypedRateLimitingInterface[Request]) {
+			w.Add(newRequest(e.Object))
 		},
-		GenericFunc: func(ctx context.Context, e event.TypedGenericEvent[*coralv1beta1.ImageSync], w workqueue.TypedRateLimitingInterface[Request]) {
-			// Do nothing
+		GenericFunc: func(ctx context.Context, e event.TypedGenericEvent[T], w workqueue.TypedRateLimitingInterface[Request]) {
+			// Generic events are only sent by the resync.
+			w.Add(newRequest(e.Object))
 		},
 	}
+}
 
-	return builder.TypedControllerManagedBy[Request](mgr).
-		WatchesRawSource(source.TypedKind(
-			mgr.GetCache(),
-			&coralv1beta1.ImageSync{},
-			h),
-		).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		Named("imagesync-watcher").
-		Complete(w)
+func newRequest(obj coralv1beta1.ImageSyncObject) Request {
+	_, cluster := obj.(*coralv1beta1.ClusterImageSync)
+	return Request{
+		NamespacedName: types.NamespacedName{
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+		},
+		UID:     obj.GetUID(),
+		Cluster: cluster,
+	}
 }
 
 // +kubebuilder:rbac:groups=coral.ctx.sh,resources=imagesyncs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=coral.ctx.sh,resources=clusterimagesyncs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 
@@ -118,16 +172,25 @@ func (w *Watcher) Reconcile(ctx context.Context, req Request) (ctrl.Result, erro
 	}
 
 	err := observer.observe(ctx, observed)
+
+	// Rebuild the ledger from the images on the node the first time that we have a node
+	// to work with.
+	if observed.Node != nil {
+		if rerr := w.restore(ctx, observed.Node); rerr != nil {
+			log.Error(rerr, "unable to restore image ledger")
+			return ctrl.Result{}, rerr
+		}
+	}
+
 	if err != nil {
 		switch {
 		case errors.Is(err, ErrNodeMatch):
-			// I'm treating this as an error just for now.  Previously I was using this to clean up
-			// the imagesync if the selectors were changed, but not anymore.  Still, I want to keep
-			// this here for now.
-			return ctrl.Result{}, nil
+			// The selectors no longer match the node, so the imagesync does not reference any of
+			// the images here anymore.  Release anything that was pulled on its behalf.
+			return ctrl.Result{}, w.releaseAll(ctx, observed.Node, observed.ImageSync.GetUID())
 		case errors.Is(err, ErrImageSyncNotFound):
-			// Imagesync has been deleted. Just return and do nothing.
-			return ctrl.Result{}, nil
+			// Imagesync has been deleted.  Release anything that was pulled on its behalf.
+			return ctrl.Result{}, w.releaseAll(ctx, observed.Node, req.UID)
 		case errors.Is(err, ErrPullSecretsNotFound):
 			// Pull secrets have been specified but none of them were found.  Return error.
 			log.Error(err, "pull secrets not found")
@@ -139,16 +202,36 @@ func (w *Watcher) Reconcile(ctx context.Context, req Request) (ctrl.Result, erro
 	}
 
 	// Handle the images that are being deleted.
-	if !observed.ImageSync.DeletionTimestamp.IsZero() {
+	if !observed.ImageSync.GetDeletionTimestamp().IsZero() {
 		log.V(2).Info("imagesync is being deleted, cleaning up")
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, w.releaseAll(ctx, observed.Node, observed.ImageSync.GetUID())
 	}
 
-	return w.process(ctx, observed.ImageSync, observed.PullSecrets)
+	return w.process(ctx, observed)
 }
 
-func (w *Watcher) process(ctx context.Context, obj *coralv1beta1.ImageSync, pullSecrets []corev1.Secret) (ctrl.Result, error) {
+func (w *Watcher) process(ctx context.Context, observed *ObservedState) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
+	obj := observed.ImageSync
+
+	refs := make([]util.Reference, 0, len(obj.GetImages()))
+	fqns := make([]string, 0, len(obj.GetImages()))
+	for _, img := range obj.GetImages() {
+		ref, err := util.ParseReference(img)
+		if err != nil {
+			log.Error(err, "skipping invalid image")
+			continue
+		}
+		refs = append(refs, ref)
+		fqns = append(fqns, ref.String())
+	}
+
+	// Release any of the images that have been removed from the spec since the last time
+	// the imagesync was processed.
+	released := w.ledger.Set(string(obj.GetUID()), fqns)
+	if err := w.release(ctx, observed.Node, obj.GetUID(), released); err != nil {
+		log.Error(err, "failed to release images")
+	}
 
 	images, err := w.imageClient.List(ctx)
 	if err != nil {
@@ -161,16 +244,26 @@ func (w *Watcher) process(ctx context.Context, obj *coralv1beta1.ImageSync, pull
 		available[img] = true
 	}
 
-	auth, err := NewAuth(pullSecrets)
+	auth, err := utilauth.NewAuth(observed.PullSecrets)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	eg, ctx := errgroup.WithContext(ctx)
+	// The controller records the digest that each tag currently resolves to.  If the node has
+	// the tag, but not at that digest, the tag has moved upstream and is pulled again.
+	digests := make(map[string]string)
+	for _, img := range obj.GetImageSyncStatus().Images {
+		digests[img.Image] = img.Digest
+	}
+
+	// A failure to pull one of the images should not stop the others from being pulled, so
+	// the group does not cancel the context on error.
+	var eg errgroup.Group
 
-	for _, img := range obj.Spec.Images {
-		fqn := util.GetImageQualifiedName(util.DefaultSearchRegistry, img)
-		if !available[fqn] {
+	for _, ref := range refs {
+		fqn := ref.String()
+		digest := digests[fqn]
+		if !available[fqn] || (digest != "" && !ref.IsDigested() && !available[ref.WithDigest(digest)]) {
 			eg.Go(func() error {
 				// TODO: Maybe pull this out so we don't create the routine if we can't acquire a processing slot.
 				w.processor.Acquire()
@@ -178,6 +271,8 @@ func (w *Watcher) process(ctx context.Context, obj *coralv1beta1.ImageSync, pull
 
 				return w.addImage(ctx, fqn, auth)
 			})
+		} else {
+			w.pulls.Done(fqn)
 		}
 	}
 
@@ -186,15 +281,157 @@ func (w *Watcher) process(ctx context.Context, obj *coralv1beta1.ImageSync, pull
 		return ctrl.Result{}, err
 	}
 
+	if err := w.pin(ctx, obj, fqns); err != nil {
+		log.Error(err, "failed to pin images")
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
-func (w *Watcher) addImage(ctx context.Context, fqn string, auth *Auth) error {
+// pin protects the images from the kubelet garbage collection when pinning has been enabled
+// on the imagesync and removes any existing pin otherwise.
+func (w *Watcher) pin(ctx context.Context, obj coralv1beta1.ImageSyncObject, fqns []string) error {
+	if obj.GetPin() {
+		return w.imageClient.Pin(ctx, string(obj.GetUID()), fqns)
+	}
+
+	return w.imageClient.Unpin(ctx, string(obj.GetUID()))
+}
+
+func (w *Watcher) addImage(ctx context.Context, fqn string, auth *utilauth.Auth) error {
 	log := ctrl.LoggerFrom(ctx, "name", fqn)
 	log.V(2).Info("adding image")
 
+	w.pulls.Start(fqn)
+
 	creds := auth.Lookup(fqn)
 	if err := w.imageClient.Pull(ctx, fqn, creds); err != nil {
+		w.pulls.Fail(fqn, imageClient.Reason(err))
+		return err
+	}
+
+	w.pulls.Done(fqn)
+	return nil
+}
+
+// restore rebuilds the ledger from the imagesyncs in the informer cache that match the node
+// and the images that are currently present on the node.  It only runs once per process.
+func (w *Watcher) restore(ctx context.Context, node *corev1.Node) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.restored {
+		return nil
+	}
+
+	images, err := w.imageClient.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	present := make(map[string]bool, len(images))
+	for _, img := range images {
+		present[img] = true
+	}
+
+	items, err := util.ListImageSyncs(ctx, w.Client)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if !item.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		matches, err := util.NodeMatches(node, item)
+		if err != nil || !matches {
+			continue
+		}
+
+		owned := make([]string, 0, len(item.GetImages()))
+		for _, img := range item.GetImages() {
+			ref, err := util.ParseReference(img)
+			if err == nil && present[ref.String()] {
+				owned = append(owned, ref.String())
+			}
+		}
+
+		w.ledger.Set(string(item.GetUID()), owned)
+	}
+
+	ctrl.LoggerFrom(ctx).V(2).Info("restored image ledger", "images", len(w.ledger.Images()))
+
+	w.restored = true
+	return nil
+}
+
+// releaseAll unpins and releases all of the images held by the imagesync.
+func (w *Watcher) releaseAll(ctx context.Context, node *corev1.Node, uid types.UID) error {
+	if err := w.imageClient.Unpin(ctx, string(uid)); err != nil {
+		return err
+	}
+
+	return w.release(ctx, node, uid, w.ledger.Remove(string(uid)))
+}
+
+// release removes images that are no longer held in the ledger as long as they are not
+// referenced by any other imagesync that matches the node.
+func (w *Watcher) release(ctx context.Context, node *corev1.Node, uid types.UID, images []string) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	observer := StateObserver{
+		Client:   w.Client,
+		NodeName: w.nodeName,
+	}
+
+	referenced, err := observer.observeReferences(ctx, node, uid)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+	for _, fqn := range images {
+		if referenced[fqn] || w.ledger.IsReferenced(fqn) {
+			continue
+		}
+
+		w.pulls.Done(fqn)
+		if err := w.removeImage(ctx, uid, fqn, referenced); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w *Watcher) removeImage(ctx context.Context, uid types.UID, fqn string, referenced map[string]bool) error {
+	log := ctrl.LoggerFrom(ctx, "name", fqn)
+
+	info, err := w.imageClient.Status(ctx, fqn)
+	if err != nil {
+		if imageClient.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// The runtime removes every tag that points at the image, so leave the image in place
+	// if any of the other tags are still referenced.
+	for _, tag := range info.Tags {
+		if tag != fqn && (referenced[tag] || w.ledger.IsReferenced(tag)) {
+			log.V(2).Info("image shares a tag with a referenced image, skipping removal", "tag", tag)
+			return nil
+		}
+	}
+
+	log.V(2).Info("removing image")
+
+	_, err = w.imageClient.Delete(ctx, string(uid), fqn)
+	if err != nil && !imageClient.IsNotFound(err) && !imageClient.IsInUse(err) {
 		return err
 	}
 
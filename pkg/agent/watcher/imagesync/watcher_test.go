@@ -15,12 +15,19 @@
 package imagesync
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
+	"ctx.sh/coral/pkg/agent/client"
+	"ctx.sh/coral/pkg/limiter"
 	"ctx.sh/coral/pkg/mock"
+	"ctx.sh/coral/pkg/store"
+	smock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -49,225 +56,296 @@ func TestWatcherTestSuite(t *testing.T) {
 	suite.Run(t, new(WatcherTestSuite))
 }
 
-//
-// func (s *WatcherTestSuite) TestReconcile_pull() {
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-//
-// 	s.client.ApplyFixtureOrDie("imagesync-agent-watcher.yaml")
-//
-// 	wq := queue.New(1)
-// 	imageClient := mock.NewMockImageClient(s.T())
-// 	imageClient.EXPECT().Pull(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/golang:latest", smock.Anything).Return(client.Info{
-// 		ID:   "golang-id",
-// 		Name: "docker.io/library/golang:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-// 	imageClient.EXPECT().Pull(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/nginx:latest", smock.Anything).Return(client.Info{
-// 		ID:   "nginx-id",
-// 		Name: "docker.io/library/nginx:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-//
-// 	watcher := &Watcher{
-// 		workQueue:   wq,
-// 		nodeName:    "node1",
-// 		imageClient: imageClient,
-// 		collection:  NewReferences(),
-// 		Client:      s.client,
-// 	}
-//
-// 	result, err := watcher.Reconcile(ctx, Request{
-// 		NamespacedName: types.NamespacedName{
-// 			Name:      "example",
-// 			Namespace: "default",
-// 		},
-// 	})
-//
-// 	s.NoError(err, "Reconcile should not return an error")
-// 	s.Empty(ctrl.Result{}, result)
-//
-// 	var node corev1.Node
-// 	err = s.client.Get(ctx, types.NamespacedName{Name: "node1"}, &node)
-// 	s.NoError(err, "Should be able to get node")
-// 	s.Contains(node.GetLabels(), "imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da")
-// 	s.Equal("present", node.GetLabels()["imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da"])
-// 	s.Contains(node.GetLabels(), "imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519")
-// 	s.Equal("present", node.GetLabels()["imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519"])
-// }
-//
-// func (s *WatcherTestSuite) TestReconcile_delete() {
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-//
-// 	// Set the labels on the node to simulate an existing successful reconcile.
-// 	var node corev1.Node
-// 	err := s.client.Get(ctx, types.NamespacedName{Name: "node1"}, &node)
-// 	s.NoError(err)
-// 	node.SetLabels(map[string]string{
-// 		"imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da": "present",
-// 		"imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519": "present",
-// 	})
-// 	err = s.client.Update(ctx, &node)
-// 	s.NoError(err)
-//
-// 	// Apply the fixture that simulates the deletion of the ImageSync resource and run the reconcile.
-// 	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-deleted.yaml")
-//
-// 	wq := queue.New(1)
-// 	imageClient := mock.NewMockImageClient(s.T())
-// 	imageClient.EXPECT().Delete(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/golang:latest").Return(client.Info{
-// 		ID:   "golang-id",
-// 		Name: "docker.io/library/golang:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-// 	imageClient.EXPECT().Delete(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/nginx:latest").Return(client.Info{
-// 		ID:   "nginx-id",
-// 		Name: "docker.io/library/nginx:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-//
-// 	watcher := &Watcher{
-// 		workQueue:   wq,
-// 		nodeName:    "node1",
-// 		imageClient: imageClient,
-// 		collection:  NewReferences(),
-// 		Client:      s.client,
-// 	}
-//
-// 	watcher.collection.Add("b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/golang:latest", "golang-id")
-// 	watcher.collection.Add("b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/nginx:latest", "nginx-id")
-//
-// 	result, err := watcher.Reconcile(ctx, Request{
-// 		NamespacedName: types.NamespacedName{
-// 			Name:      "example",
-// 			Namespace: "default",
-// 		},
-// 	})
-//
-// 	s.NoError(err)
-// 	s.Empty(ctrl.Result{}, result)
-//
-// 	var updatedNode corev1.Node
-// 	err = s.client.Get(ctx, types.NamespacedName{Name: "node1"}, &updatedNode)
-// 	s.NoError(err)
-// 	s.NotContains(updatedNode.GetLabels(), "imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da")
-// 	s.NotContains(updatedNode.GetLabels(), "imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519")
-// }
-//
-// func (s *WatcherTestSuite) TestReconcile_delete_single() {
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-//
-// 	// Set the labels on the node to simulate an existing successful reconcile.
-// 	var node corev1.Node
-// 	err := s.client.Get(ctx, types.NamespacedName{Name: "node1"}, &node)
-// 	s.NoError(err)
-// 	node.SetLabels(map[string]string{
-// 		"imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da": "present",
-// 		"imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519": "present",
-// 	})
-// 	err = s.client.Update(ctx, &node)
-// 	s.NoError(err)
-//
-// 	// Apply the fixture that simulates the deletion of the ImageSync resource and run the reconcile.
-// 	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-deleted-single.yaml")
-//
-// 	wq := queue.New(1)
-// 	imageClient := mock.NewMockImageClient(s.T())
-// 	imageClient.EXPECT().Pull(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/golang:latest", smock.Anything).Return(client.Info{
-// 		ID:   "golang-id",
-// 		Name: "docker.io/library/golang:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-// 	imageClient.EXPECT().Delete(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/nginx:latest").Return(client.Info{
-// 		ID:   "nginx-id",
-// 		Name: "docker.io/library/nginx:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-//
-// 	watcher := &Watcher{
-// 		workQueue:   wq,
-// 		nodeName:    "node1",
-// 		imageClient: imageClient,
-// 		collection:  NewReferences(),
-// 		Client:      s.client,
-// 	}
-//
-// 	watcher.collection.Add("b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/golang:latest", "golang-id")
-// 	watcher.collection.Add("b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/nginx:latest", "nginx-id")
-//
-// 	result, err := watcher.Reconcile(ctx, Request{
-// 		NamespacedName: types.NamespacedName{
-// 			Name:      "example",
-// 			Namespace: "default",
-// 		},
-// 	})
-//
-// 	s.NoError(err)
-// 	s.Empty(ctrl.Result{}, result)
-//
-// 	var updatedNode corev1.Node
-// 	err = s.client.Get(ctx, types.NamespacedName{Name: "node1"}, &updatedNode)
-// 	s.NoError(err)
-// 	s.Contains(updatedNode.GetLabels(), "imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da")
-// 	s.NotContains(updatedNode.GetLabels(), "imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519")
-// }
-//
-// func (s *WatcherTestSuite) TestReconcile_node_selectors_changed() {
-// 	ctx, cancel := context.WithCancel(context.Background())
-// 	defer cancel()
-//
-// 	// Set the labels on the node to simulate an existing successful reconcile.
-// 	var node corev1.Node
-// 	err := s.client.Get(ctx, types.NamespacedName{Name: "node1"}, &node)
-// 	s.NoError(err)
-// 	node.SetLabels(map[string]string{
-// 		"imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da": "present",
-// 		"imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519": "present",
-// 	})
-// 	err = s.client.Update(ctx, &node)
-// 	s.NoError(err)
-//
-// 	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-node-selector.yaml")
-//
-// 	wq := queue.New(1)
-// 	imageClient := mock.NewMockImageClient(s.T())
-// 	imageClient.EXPECT().Delete(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/golang:latest").Return(client.Info{
-// 		ID:   "golang-id",
-// 		Name: "docker.io/library/golang:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-// 	imageClient.EXPECT().Delete(smock.Anything, "b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/nginx:latest").Return(client.Info{
-// 		ID:   "nginx-id",
-// 		Name: "docker.io/library/nginx:latest",
-// 		Tags: []string{"latest"},
-// 	}, nil).Once()
-//
-// 	watcher := &Watcher{
-// 		workQueue:   wq,
-// 		nodeName:    "node1",
-// 		imageClient: imageClient,
-// 		collection:  NewReferences(),
-// 		Client:      s.client,
-// 	}
-//
-// 	watcher.collection.Add("b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/golang:latest", "golang-id")
-// 	watcher.collection.Add("b7f01748-4d55-4bc3-939a-a458c19ca533", "docker.io/library/nginx:latest", "nginx-id")
-//
-// 	result, err := watcher.Reconcile(ctx, Request{
-// 		NamespacedName: types.NamespacedName{
-// 			Name:      "example",
-// 			Namespace: "default",
-// 		},
-// 	})
-//
-// 	s.NoError(err)
-// 	s.Empty(ctrl.Result{}, result)
-//
-// 	var updatedNode corev1.Node
-// 	err = s.client.Get(ctx, types.NamespacedName{Name: "node1"}, &updatedNode)
-// 	s.NoError(err)
-// 	s.NotContains(updatedNode.GetLabels(), "imagesync.coral.ctx.sh/7f88bbf3e814bc26c05c719c470303da")
-// 	s.NotContains(updatedNode.GetLabels(), "imagesync.coral.ctx.sh/819de3aee13d29b3d12a28494ac35519")
-// }
+const watcherTestUID = "b7f01748-4d55-4bc3-939a-a458c19ca533"
+
+func (s *WatcherTestSuite) newWatcher(ic *mock.MockImageClient) *Watcher {
+	return &Watcher{
+		processor:   limiter.New(1),
+		nodeName:    "node1",
+		imageClient: ic,
+		ledger:      store.NewLedger(),
+		pulls:       store.NewPulls(),
+		restored:    true,
+		Client:      s.client,
+	}
+}
+
+func (s *WatcherTestSuite) reconcile(ctx context.Context, w *Watcher) {
+	result, err := w.Reconcile(ctx, Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "example",
+			Namespace: "default",
+		},
+		UID: watcherTestUID,
+	})
+
+	s.NoError(err)
+	s.Equal(ctrl.Result{}, result)
+}
+
+func (s *WatcherTestSuite) TestReconcile_pull() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher.yaml")
+
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	ic.EXPECT().List(smock.Anything).Return([]string{}, nil).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/golang:latest", smock.Anything).Return(nil).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/nginx:latest", smock.Anything).Return(nil).Once()
+
+	watcher := s.newWatcher(ic)
+	s.reconcile(ctx, watcher)
+
+	s.True(watcher.ledger.IsReferenced("docker.io/library/golang:latest"))
+	s.True(watcher.ledger.IsReferenced("docker.io/library/nginx:latest"))
+}
+
+func (s *WatcherTestSuite) TestReconcile_pull_cluster() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("clusterimagesync.yaml")
+
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, "5f0c1e2a-8d3b-4c6e-9a7f-2b1d4e6f8a90").Return(nil).Maybe()
+	ic.EXPECT().List(smock.Anything).Return([]string{}, nil).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/golang:latest", smock.Anything).Return(nil).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/nginx:latest", smock.Anything).Return(nil).Once()
+
+	watcher := s.newWatcher(ic)
+	result, err := watcher.Reconcile(ctx, Request{
+		NamespacedName: types.NamespacedName{
+			Name: "example-cluster",
+		},
+		UID:     "5f0c1e2a-8d3b-4c6e-9a7f-2b1d4e6f8a90",
+		Cluster: true,
+	})
+	s.NoError(err)
+	s.Equal(ctrl.Result{}, result)
+
+	s.True(watcher.ledger.IsReferenced("docker.io/library/golang:latest"))
+	s.True(watcher.ledger.IsReferenced("docker.io/library/nginx:latest"))
+}
+
+func (s *WatcherTestSuite) TestReconcile_pull_failed() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher.yaml")
+
+	// A failed image should not stop the other images from being pulled.
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().List(smock.Anything).Return([]string{}, nil).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/golang:latest", smock.Anything).Return(client.ErrUnauthorized).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/nginx:latest", smock.Anything).Return(nil).Once()
+
+	watcher := s.newWatcher(ic)
+	_, err := watcher.Reconcile(ctx, Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "example",
+			Namespace: "default",
+		},
+		UID: watcherTestUID,
+	})
+	s.ErrorIs(err, client.ErrUnauthorized)
+
+	pull, ok := watcher.pulls.Get("docker.io/library/golang:latest")
+	s.True(ok)
+	s.True(pull.Failed)
+	s.Equal("unauthorized", pull.Reason)
+
+	_, ok = watcher.pulls.Get("docker.io/library/nginx:latest")
+	s.False(ok)
+
+	// Once the image is available the failure is cleared.
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	ic.EXPECT().List(smock.Anything).Return([]string{
+		"docker.io/library/golang:latest",
+		"docker.io/library/nginx:latest",
+	}, nil).Once()
+	s.reconcile(ctx, watcher)
+
+	_, ok = watcher.pulls.Get("docker.io/library/golang:latest")
+	s.False(ok)
+}
+
+func (s *WatcherTestSuite) TestReconcile_delete() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-deleted.yaml")
+
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	for _, name := range []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"} {
+		ic.EXPECT().Status(smock.Anything, name).Return(client.Info{
+			ID:   name + "-id",
+			Name: name,
+			Tags: []string{name},
+		}, nil).Once()
+		ic.EXPECT().Delete(smock.Anything, watcherTestUID, name).Return(client.Info{}, nil).Once()
+	}
+
+	watcher := s.newWatcher(ic)
+	watcher.ledger.Set(watcherTestUID, []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+
+	s.reconcile(ctx, watcher)
+	s.False(watcher.ledger.IsReferenced("docker.io/library/golang:latest"))
+	s.False(watcher.ledger.IsReferenced("docker.io/library/nginx:latest"))
+}
+
+func (s *WatcherTestSuite) TestReconcile_delete_single() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-deleted-single.yaml")
+
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	ic.EXPECT().Status(smock.Anything, "docker.io/library/nginx:latest").Return(client.Info{
+		ID:   "nginx-id",
+		Name: "docker.io/library/nginx:latest",
+		Tags: []string{"docker.io/library/nginx:latest"},
+	}, nil).Once()
+	ic.EXPECT().Delete(smock.Anything, watcherTestUID, "docker.io/library/nginx:latest").Return(client.Info{}, nil).Once()
+	ic.EXPECT().List(smock.Anything).Return([]string{"docker.io/library/golang:latest"}, nil).Once()
+
+	watcher := s.newWatcher(ic)
+	watcher.ledger.Set(watcherTestUID, []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+
+	s.reconcile(ctx, watcher)
+	s.True(watcher.ledger.IsReferenced("docker.io/library/golang:latest"))
+	s.False(watcher.ledger.IsReferenced("docker.io/library/nginx:latest"))
+}
+
+func (s *WatcherTestSuite) TestReconcile_delete_shared() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-deleted.yaml")
+
+	// Another imagesync still owns nginx, so only golang should be removed.
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	ic.EXPECT().Status(smock.Anything, "docker.io/library/golang:latest").Return(client.Info{
+		ID:   "golang-id",
+		Name: "docker.io/library/golang:latest",
+		Tags: []string{"docker.io/library/golang:latest"},
+	}, nil).Once()
+	ic.EXPECT().Delete(smock.Anything, watcherTestUID, "docker.io/library/golang:latest").Return(client.Info{}, nil).Once()
+
+	watcher := s.newWatcher(ic)
+	watcher.ledger.Set(watcherTestUID, []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+	watcher.ledger.Set("other", []string{"docker.io/library/nginx:latest"})
+
+	s.reconcile(ctx, watcher)
+	s.True(watcher.ledger.IsReferenced("docker.io/library/nginx:latest"))
+}
+
+func (s *WatcherTestSuite) TestReconcile_in_use() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-deleted.yaml")
+
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	for _, name := range []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"} {
+		ic.EXPECT().Status(smock.Anything, name).Return(client.Info{
+			ID:   name + "-id",
+			Name: name,
+			Tags: []string{name},
+		}, nil).Once()
+		ic.EXPECT().Delete(smock.Anything, watcherTestUID, name).Return(client.Info{}, client.ErrInUse).Once()
+	}
+
+	watcher := s.newWatcher(ic)
+	watcher.ledger.Set(watcherTestUID, []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+
+	s.reconcile(ctx, watcher)
+}
+
+func (s *WatcherTestSuite) TestReconcile_node_selectors_changed() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-node-selector.yaml")
+
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	for _, name := range []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"} {
+		ic.EXPECT().Status(smock.Anything, name).Return(client.Info{
+			ID:   name + "-id",
+			Name: name,
+			Tags: []string{name},
+		}, nil).Once()
+		ic.EXPECT().Delete(smock.Anything, watcherTestUID, name).Return(client.Info{}, nil).Once()
+	}
+
+	watcher := s.newWatcher(ic)
+	watcher.ledger.Set(watcherTestUID, []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+
+	s.reconcile(ctx, watcher)
+}
+
+func (s *WatcherTestSuite) TestReconcile_restore() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher.yaml")
+
+	// Only golang is on the node after a restart, so the ledger should only be restored with
+	// golang and nginx is pulled.
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	ic.EXPECT().List(smock.Anything).Return([]string{"docker.io/library/golang:latest"}, nil).Twice()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/nginx:latest", smock.Anything).Return(nil).Once()
+
+	watcher := s.newWatcher(ic)
+	watcher.restored = false
+
+	s.reconcile(ctx, watcher)
+	s.True(watcher.restored)
+	s.Equal([]string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"}, watcher.ledger.Images())
+}
+
+func (s *WatcherTestSuite) TestReconcile_pin() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-pin.yaml")
+
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().List(smock.Anything).Return([]string{"docker.io/library/golang:latest"}, nil).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/nginx:latest", smock.Anything).Return(nil).Once()
+	ic.EXPECT().Pin(smock.Anything, watcherTestUID, []string{
+		"docker.io/library/golang:latest",
+		"docker.io/library/nginx:latest",
+	}).Return(nil).Once()
+
+	watcher := s.newWatcher(ic)
+	s.reconcile(ctx, watcher)
+}
+
+func (s *WatcherTestSuite) TestReconcile_tag_moved() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.client.ApplyFixtureOrDie("imagesync-agent-watcher-digest.yaml")
+
+	// Both tags are on the node, but golang is at an old digest so it is pulled again.
+	ic := mock.NewMockImageClient(s.T())
+	ic.EXPECT().Unpin(smock.Anything, watcherTestUID).Return(nil).Maybe()
+	ic.EXPECT().List(smock.Anything).Return([]string{
+		"docker.io/library/golang:latest",
+		"docker.io/library/golang@sha256:old",
+		"docker.io/library/nginx:latest",
+		"docker.io/library/nginx@sha256:nginx",
+	}, nil).Once()
+	ic.EXPECT().Pull(smock.Anything, "docker.io/library/golang:latest", smock.Anything).Return(nil).Once()
+
+	watcher := s.newWatcher(ic)
+	s.reconcile(ctx, watcher)
+}
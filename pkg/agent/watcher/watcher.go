@@ -16,10 +16,12 @@ package watcher
 
 import (
 	"context"
+	"time"
 
 	"ctx.sh/coral/pkg/agent/client"
 	"ctx.sh/coral/pkg/agent/watcher/imagesync"
 	"ctx.sh/coral/pkg/limiter"
+	"ctx.sh/coral/pkg/store"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -28,6 +30,10 @@ type Options struct {
 	MaxConcurrentReconcilers int
 	MaxConcurrentPullers     int
 	NodeName                 string
+	Ledger                   *store.Ledger
+	Pulls                    *store.Pulls
+	ResyncInterval           time.Duration
+	ResyncJitter             float64
 }
 
 type Watcher struct{}
@@ -44,7 +50,11 @@ func SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts *Options) erro
 		Limiter:                  el,
 		MaxConcurrentReconcilers: opts.MaxConcurrentReconcilers,
 		ImageClient:              imageClient,
+		Ledger:                   opts.Ledger,
+		Pulls:                    opts.Pulls,
 		NodeName:                 opts.NodeName,
+		ResyncInterval:           opts.ResyncInterval,
+		ResyncJitter:             opts.ResyncJitter,
 	}); err != nil {
 		return err
 	}
@@ -0,0 +1,61 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+// +kubebuilder:docs-gen:collapse=Apache License
+
+// Condition types that are set on the imagesync and mirror status.
+const (
+	// ConditionReady is true when all of the images have been synced to the nodes or mirrored
+	// to the registry.
+	ConditionReady = "Ready"
+	// ConditionProgressing is true while images are still being synced or mirrored.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true when one or more images failed to sync or mirror.
+	ConditionDegraded = "Degraded"
+)
+
+// Condition reasons that are set on the imagesync and mirror status.
+const (
+	// ReasonAsExpected is used when a condition is false and nothing is wrong.
+	ReasonAsExpected = "AsExpected"
+	// ReasonAvailable is used when all images are available on all of the nodes.
+	ReasonAvailable = "Available"
+	// ReasonPending is used when images are still pending on one or more nodes.
+	ReasonPending = "Pending"
+	// ReasonNoMatchingNodes is used when no nodes match the imagesync node selector.
+	ReasonNoMatchingNodes = "NoMatchingNodes"
+	// ReasonInvalidNodeSelector is used when the imagesync node selector or affinity is invalid.
+	ReasonInvalidNodeSelector = "InvalidNodeSelector"
+	// ReasonPullFailed is used when one or more nodes failed to pull an image.
+	ReasonPullFailed = "PullFailed"
+	// ReasonMirrored is used when all images have been mirrored.
+	ReasonMirrored = "Mirrored"
+	// ReasonMirroring is used while the images are being mirrored.
+	ReasonMirroring = "Mirroring"
+	// ReasonMirrorFailed is used when one or more images failed to mirror.
+	ReasonMirrorFailed = "MirrorFailed"
+	// ReasonPolicyRejected is used when one or more images were rejected by the image policy.
+	ReasonPolicyRejected = "PolicyRejected"
+	// ReasonInvalidImagePolicy is used when the image policies for a mirror are invalid.
+	ReasonInvalidImagePolicy = "InvalidImagePolicy"
+	// ReasonInvalidRegistryConfig is used when the registry configuration of a mirror can not
+	// be resolved.
+	ReasonInvalidRegistryConfig = "InvalidRegistryConfig"
+	// ReasonPruned is used when an image has been deleted from the registry.
+	ReasonPruned = "Pruned"
+	// ReasonPruneFailed is used when an image could not be deleted from the registry.
+	ReasonPruneFailed = "PruneFailed"
+)
@@ -29,10 +29,20 @@ func defaultedImageSync(obj *ImageSync) {
 	defaultedImageSyncSpec(&obj.Spec)
 }
 
+func defaultedClusterImageSyncSpec(obj *ClusterImageSyncSpec) {}
+
+func defaultedClusterImageSync(obj *ClusterImageSync) {
+	defaultedClusterImageSyncSpec(&obj.Spec)
+}
+
 func defaultedMirrorSpec(obj *MirrorSpec) {
 	if obj.CopyAllArchitectures == nil {
 		obj.CopyAllArchitectures = ptr.To(false)
 	}
+
+	if obj.DeletionPolicy == "" {
+		obj.DeletionPolicy = MirrorDeletionPolicyRetain
+	}
 }
 
 func defaultedMirror(obj *Mirror) {
@@ -44,6 +54,8 @@ func Defaulted(obj runtime.Object) {
 	switch obj := obj.(type) { //nolint:gocritic
 	case *ImageSync:
 		defaultedImageSync(obj)
+	case *ClusterImageSync:
+		defaultedClusterImageSync(obj)
 	case *Mirror:
 		defaultedMirror(obj)
 	}
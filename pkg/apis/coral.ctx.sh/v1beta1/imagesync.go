@@ -0,0 +1,145 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+// +kubebuilder:docs-gen:collapse=Apache License
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// +kubebuilder:docs-gen:collapse=Go imports
+
+// ImageSyncObject is implemented by both the namespaced ImageSync and the cluster scoped
+// ClusterImageSync so that the agents and the controller can handle them the same way.
+// +kubebuilder:object:generate=false
+type ImageSyncObject interface {
+	metav1.Object
+	runtime.Object
+	// GetImages returns the images to fetch.
+	GetImages() []string
+	// GetNodeSelector returns the selectors for the nodes that the images are synced to.
+	GetNodeSelector() []NodeSelector
+	// GetNodeAffinity returns the node affinity for the nodes that the images are synced to.
+	GetNodeAffinity() *corev1.NodeAffinity
+	// GetTolerations returns the tolerations for the taints of the nodes.
+	GetTolerations() []corev1.Toleration
+	// GetPin returns true if the images should be protected from the kubelet garbage collection.
+	GetPin() bool
+	// GetPullSecrets returns the namespaced names of the pull secrets.
+	GetPullSecrets() []types.NamespacedName
+	// GetImageSyncStatus returns the status of the imagesync.
+	GetImageSyncStatus() *ImageSyncStatus
+}
+
+func (i *ImageSync) GetImages() []string {
+	return i.Spec.Images
+}
+
+func (i *ImageSync) GetNodeSelector() []NodeSelector {
+	return i.Spec.NodeSelector
+}
+
+func (i *ImageSync) GetNodeAffinity() *corev1.NodeAffinity {
+	return i.Spec.NodeAffinity
+}
+
+func (i *ImageSync) GetTolerations() []corev1.Toleration {
+	return i.Spec.Tolerations
+}
+
+func (i *ImageSync) GetPin() bool {
+	return i.Spec.Pin
+}
+
+// GetPullSecrets returns the pull secrets which are always in the namespace of the imagesync.
+func (i *ImageSync) GetPullSecrets() []types.NamespacedName {
+	secrets := make([]types.NamespacedName, 0, len(i.Spec.ImagePullSecrets))
+	for _, ref := range i.Spec.ImagePullSecrets {
+		secrets = append(secrets, types.NamespacedName{
+			Name:      ref.Name,
+			Namespace: i.GetNamespace(),
+		})
+	}
+
+	return secrets
+}
+
+func (i *ImageSync) GetImageSyncStatus() *ImageSyncStatus {
+	return &i.Status
+}
+
+func (c *ClusterImageSync) GetImages() []string {
+	return c.Spec.Images
+}
+
+func (c *ClusterImageSync) GetNodeSelector() []NodeSelector {
+	return c.Spec.NodeSelector
+}
+
+func (c *ClusterImageSync) GetNodeAffinity() *corev1.NodeAffinity {
+	return c.Spec.NodeAffinity
+}
+
+func (c *ClusterImageSync) GetTolerations() []corev1.Toleration {
+	return c.Spec.Tolerations
+}
+
+func (c *ClusterImageSync) GetPin() bool {
+	return c.Spec.Pin
+}
+
+// GetPullSecrets returns the pull secrets by the namespace and name that they reference.
+func (c *ClusterImageSync) GetPullSecrets() []types.NamespacedName {
+	secrets := make([]types.NamespacedName, 0, len(c.Spec.ImagePullSecrets))
+	for _, ref := range c.Spec.ImagePullSecrets {
+		secrets = append(secrets, types.NamespacedName{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+		})
+	}
+
+	return secrets
+}
+
+func (c *ClusterImageSync) GetImageSyncStatus() *ImageSyncStatus {
+	return &c.Status
+}
+
+// Objects returns the items in the list as imagesync objects.
+func (l *ImageSyncList) Objects() []ImageSyncObject {
+	objs := make([]ImageSyncObject, 0, len(l.Items))
+	for i := range l.Items {
+		objs = append(objs, &l.Items[i])
+	}
+
+	return objs
+}
+
+// Objects returns the items in the list as imagesync objects.
+func (l *ClusterImageSyncList) Objects() []ImageSyncObject {
+	objs := make([]ImageSyncObject, 0, len(l.Items))
+	for i := range l.Items {
+		objs = append(objs, &l.Items[i])
+	}
+
+	return objs
+}
+
+var _ ImageSyncObject = &ImageSync{}
+var _ ImageSyncObject = &ClusterImageSync{}
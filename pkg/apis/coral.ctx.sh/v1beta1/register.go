@@ -54,8 +54,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&ImageSync{},
 		&ImageSyncList{},
+		&ClusterImageSync{},
+		&ClusterImageSyncList{},
 		&Mirror{},
 		&MirrorList{},
+		&ImagePolicy{},
+		&ImagePolicyList{},
+		&ClusterImagePolicy{},
+		&ClusterImagePolicyList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -33,6 +33,20 @@ const (
 	ImageSyncContainerIncludeAnnotation = ImageSyncLabel + "/include-containers"
 	ImageSyncContainerExcludeAnnotation = ImageSyncLabel + "/exclude-containers"
 	ImageSyncInjectedAnnotation         = ImageSyncLabel + "/injected"
+	ImageSyncAffinityAnnotation         = ImageSyncLabel + "/affinity"
+	ImageAvailableLabelValue            = "available"
+	ImageSyncGeneratedLabel             = ImageSyncLabel + "/generated"
+	ImageSyncWarmAnnotation             = ImageSyncLabel + "/warm"
+	ImageSyncWarmTimeoutAnnotation      = ImageSyncLabel + "/warm-timeout"
+	ImageSyncWarmingGate                = ImageSyncLabel + "/warming"
+	ImageSyncMinNodesAnnotation         = ImageSyncLabel + "/min-nodes"
+	ImageSyncMaxWaitAnnotation          = ImageSyncLabel + "/max-wait"
+	ImageSyncAvailableGate              = ImageSyncLabel + "/available"
+	ImageSyncRewriteAnnotation          = ImageSyncLabel + "/rewrite"
+	ImageSyncEnforceAnnotation          = ImageSyncLabel + "/enforce"
+	ImageSyncPinDigestsAnnotation       = ImageSyncLabel + "/pin-digests"
+	ImageSyncPinnedDigestsAnnotation    = ImageSyncLabel + "/pinned-digests"
+	ImageSyncOriginalImagesAnnotation   = ImageSyncLabel + "/original-images"
 )
 
 type NodeSelector struct {
@@ -53,8 +67,26 @@ type ImageSyncSpec struct {
 	NodeSelector []NodeSelector `json:"nodeSelector,omitempty"`
 	// +optional
 	// +nullable
+	// NodeAffinity restricts the nodes that the images are synced to using the standard
+	// node affinity rules.  Only the requiredDuringSchedulingIgnoredDuringExecution terms
+	// are evaluated, the preferred terms are ignored.  The affinity is ANDed with the
+	// nodeSelector.
+	NodeAffinity *corev1.NodeAffinity `json:"nodeAffinity,omitempty"`
+	// +optional
+	// +nullable
+	// +listType=atomic
+	// Tolerations limits the sync to the nodes whose NoSchedule and NoExecute taints are
+	// tolerated.  Taints are not considered when no tolerations are given.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// +optional
+	// +nullable
 	// ImagePullSecrets is a list of secrets to use when pulling the image.
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// +optional
+	// Pin protects the images from the kubelet image garbage collection while the imagesync
+	// exists.  The agents hold a pod sandbox on each node with containers that reference the
+	// images so that the kubelet considers them in use.
+	Pin bool `json:"pin,omitempty"`
 }
 
 // +genclient
@@ -63,10 +95,13 @@ type ImageSyncSpec struct {
 // +kubebuilder:validation:Required
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=img,singular=images
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether all images are available on the nodes"
 // +kubebuilder:printcolumn:name="Images",type="integer",JSONPath=".status.totalImages",description="The number of total images managed by the object"
 // +kubebuilder:printcolumn:name="Nodes Total",type="integer",JSONPath=".status.totalNodes",description="The number of nodes matching the selector (if any)"
 // +kubebuilder:printcolumn:name="Nodes Available",type="integer",JSONPath=".status.condition.available",description="The number of images that are currently available on the nodes"
 // +kubebuilder:printcolumn:name="Nodes Pending",type="integer",JSONPath=".status.condition.pending",description="The number of images that are currently pending on the nodes"
+// +kubebuilder:printcolumn:name="Nodes Failed",type="integer",JSONPath=".status.condition.failed",description="The number of nodes that failed to pull one or more images"
+// +kubebuilder:printcolumn:name="Nodes Pinned",type="integer",JSONPath=".status.condition.pinned",description="The number of nodes that have all images pinned",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ImageSync is an external image that will be mirrored to each configured node.
@@ -86,6 +121,75 @@ type ImageSyncList struct {
 	Items           []ImageSync `json:"items"`
 }
 
+// ClusterImageSyncSpec is the spec for a ClusterImageSync resource.
+type ClusterImageSyncSpec struct {
+	// +required
+	// +listType=atomic
+	// Images to fetch.
+	Images []string `json:"images"`
+	// +optional
+	// +nullable
+	// NodeSelector defines which nodes the image should be synced to.
+	NodeSelector []NodeSelector `json:"nodeSelector,omitempty"`
+	// +optional
+	// +nullable
+	// NodeAffinity restricts the nodes that the images are synced to using the standard
+	// node affinity rules.  Only the requiredDuringSchedulingIgnoredDuringExecution terms
+	// are evaluated, the preferred terms are ignored.  The affinity is ANDed with the
+	// nodeSelector.
+	NodeAffinity *corev1.NodeAffinity `json:"nodeAffinity,omitempty"`
+	// +optional
+	// +nullable
+	// +listType=atomic
+	// Tolerations limits the sync to the nodes whose NoSchedule and NoExecute taints are
+	// tolerated.  Taints are not considered when no tolerations are given.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// +optional
+	// +nullable
+	// ImagePullSecrets is a list of secrets to use when pulling the image.  As the resource
+	// is cluster scoped, both the namespace and the name of each secret are required.
+	ImagePullSecrets []corev1.SecretReference `json:"imagePullSecrets,omitempty"`
+	// +optional
+	// Pin protects the images from the kubelet image garbage collection while the
+	// clusterimagesync exists.
+	Pin bool `json:"pin,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
+// +kubebuilder:validation:Required
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=cimg,singular=clusterimagesync
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether all images are available on the nodes"
+// +kubebuilder:printcolumn:name="Images",type="integer",JSONPath=".status.totalImages",description="The number of total images managed by the object"
+// +kubebuilder:printcolumn:name="Nodes Total",type="integer",JSONPath=".status.totalNodes",description="The number of nodes matching the selector (if any)"
+// +kubebuilder:printcolumn:name="Nodes Available",type="integer",JSONPath=".status.condition.available",description="The number of images that are currently available on the nodes"
+// +kubebuilder:printcolumn:name="Nodes Pending",type="integer",JSONPath=".status.condition.pending",description="The number of images that are currently pending on the nodes"
+// +kubebuilder:printcolumn:name="Nodes Failed",type="integer",JSONPath=".status.condition.failed",description="The number of nodes that failed to pull one or more images"
+// +kubebuilder:printcolumn:name="Nodes Pinned",type="integer",JSONPath=".status.condition.pinned",description="The number of nodes that have all images pinned",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterImageSync is a cluster scoped set of external images that will be mirrored to each
+// configured node.  It is intended for images that are shared across the platform, such as
+// sidecars and node agents, that do not belong to any one namespace.
+type ClusterImageSync struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ClusterImageSyncSpec `json:"spec"`
+	// +optional
+	Status ImageSyncStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ClusterImageSyncList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterImageSync `json:"items"`
+}
+
 type ImageSyncState string
 
 const (
@@ -103,6 +207,23 @@ type ImageSyncCondition struct {
 	// +required
 	// Pending is the number of images that are currently pending on the nodes.
 	Pending int `json:"pending"`
+	// +optional
+	// Failed is the number of nodes that failed to pull one or more of the images.
+	Failed int `json:"failed,omitempty"`
+	// +optional
+	// Pinned is the number of nodes that have all of the images pinned.
+	Pinned int `json:"pinned,omitempty"`
+}
+
+// ImageSyncFailure contains the reason that an image failed to pull and the number of
+// nodes that failed for that reason.
+type ImageSyncFailure struct {
+	// +required
+	// Reason is the reason reported by the nodes for the failure.
+	Reason string `json:"reason"`
+	// +required
+	// Nodes is the number of nodes that failed with the reason.
+	Nodes int `json:"nodes"`
 }
 
 // ImageSyncImage contains details about an image that is being synced.
@@ -112,11 +233,25 @@ type ImageSyncImage struct {
 	// being parsed and represented on the agents, nodes, and webhooks.
 	Image string `json:"image"`
 	// +optional
+	// Digest is the most recently resolved digest of the image tag.  Nodes are only counted
+	// as available once they have the image at this digest.
+	Digest string `json:"digest,omitempty"`
+	// +optional
 	// Available is the number of nodes that have the image available.
 	Available int `json:"available"`
 	// +optional
 	// Pending is the number of nodes that are pending image download.
 	Pending int `json:"pending"`
+	// +optional
+	// Failed is the number of nodes that failed to pull the image.  Failed nodes are not
+	// counted as pending.
+	Failed int `json:"failed,omitempty"`
+	// +optional
+	// Failures are the reasons that the nodes failed to pull the image.
+	Failures []ImageSyncFailure `json:"failures,omitempty"`
+	// +optional
+	// Pinned is the number of nodes that have the image pinned.
+	Pinned int `json:"pinned,omitempty"`
 }
 
 // ImageSyncStatus is the status for a WatchSet resource.
@@ -136,8 +271,64 @@ type ImageSyncStatus struct {
 	// +optional
 	// LastUpdated is the last time the status was updated.
 	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+	// +optional
+	// ObservedGeneration is the most recent generation of the imagesync that the status
+	// reflects.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// Conditions are the Ready, Progressing and Degraded conditions of the imagesync.
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// CABundleSource selects a PEM encoded CA bundle from a ConfigMap or a Secret in the
+// namespace of the mirror.  Exactly one of the sources must be set.
+type CABundleSource struct {
+	// +optional
+	// ConfigMapKeyRef selects the key of a ConfigMap that holds the CA bundle.
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// +optional
+	// SecretKeyRef selects the key of a Secret that holds the CA bundle.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
+// MirrorRegistry configures the connection to a registry that the images are copied from.
+type MirrorRegistry struct {
+	// +required
+	// Host is the registry hostname, including the port if there is one.
+	Host string `json:"host"`
+	// +optional
+	// CA is a CA bundle that is trusted in addition to the system CAs when verifying the
+	// registry certificate.
+	CA *CABundleSource `json:"ca,omitempty"`
+	// +optional
+	// ClientCertificate references a kubernetes.io/tls Secret with the client certificate
+	// and key that are presented to the registry.
+	ClientCertificate *corev1.LocalObjectReference `json:"clientCertificate,omitempty"`
+	// +optional
+	// Insecure disables the verification of the registry certificate and allows the
+	// registry to be reached over plain HTTP.
+	Insecure bool `json:"insecure,omitempty"`
+	// +optional
+	// Proxy is the URL of the proxy that is used to connect to the registry.
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// MirrorDeletionPolicy determines what happens to the mirrored images in the coral registry
+// when they are removed from a mirror or the mirror is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type MirrorDeletionPolicy string
+
+const (
+	// MirrorDeletionPolicyRetain keeps the mirrored images in the registry.
+	MirrorDeletionPolicyRetain MirrorDeletionPolicy = "Retain"
+	// MirrorDeletionPolicyDelete deletes the mirrored images from the registry.
+	MirrorDeletionPolicyDelete MirrorDeletionPolicy = "Delete"
+)
+
 type MirrorSpec struct {
 	// +optional
 	// +nullable
@@ -151,6 +342,24 @@ type MirrorSpec struct {
 	// CopyAllArchitectures determines whether to copy all available architectures (true)
 	// or only the system architecture (false). Defaults to true for multi-arch support.
 	CopyAllArchitectures *bool `json:"copyAllArchitectures,omitempty"`
+	// +optional
+	// SyncInterval is the interval at which the images are checked against the source
+	// registry.  Images are copied again when the source manifest has changed, or when the copy
+	// is missing from the coral registry.  Images are only copied when the mirror changes
+	// if it is not set.
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+	// +optional
+	// DeletionPolicy determines whether the mirrored images are deleted from the coral
+	// registry when they are removed from the mirror or the mirror is deleted.  Images that
+	// are still mirrored by another mirror are never deleted.  Defaults to Retain.
+	DeletionPolicy MirrorDeletionPolicy `json:"deletionPolicy,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=host
+	// Registries configures the connections to the source registries.  The registry
+	// certificates are verified against the system CAs unless a registry is configured
+	// otherwise.
+	Registries []MirrorRegistry `json:"registries,omitempty"`
 }
 
 // +genclient
@@ -159,6 +368,7 @@ type MirrorSpec struct {
 // +kubebuilder:validation:Required
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=mi,singular=mirror
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether all images have been mirrored"
 // +kubebuilder:printcolumn:name="Images",type="integer",JSONPath=".status.totalImages",description="The number of total images managed by the object"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
@@ -171,10 +381,60 @@ type Mirror struct {
 	Status MirrorStatus `json:"status"`
 }
 
+// MirrorImage is the mirror state of a single image.
+type MirrorImage struct {
+	// +required
+	// Image is the fully qualified source image with tag.
+	Image string `json:"image"`
+	// +required
+	// Destination is the reference of the copy in the coral registry.
+	Destination string `json:"destination"`
+	// +optional
+	// Digest is the digest of the manifest that was copied to the coral registry.
+	Digest string `json:"digest,omitempty"`
+	// +optional
+	// SourceDigest is the digest of the source manifest when the image was last copied.
+	SourceDigest string `json:"sourceDigest,omitempty"`
+	// +optional
+	// +listType=atomic
+	// Platforms are the os/architecture[/variant] platforms that were copied.  It is empty
+	// when the platform of a single manifest could not be determined.
+	Platforms []string `json:"platforms,omitempty"`
+	// +optional
+	// LastSyncTime is the last time that the image was successfully copied to the coral
+	// registry.  Images that have never been copied do not have a sync time.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// +optional
+	// LastError is the error of the last copy if it failed.
+	LastError string `json:"lastError,omitempty"`
+	// +optional
+	// ConsecutiveFailures is the number of copies that have failed in a row.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+	// +optional
+	// Rejection is the reason that the image policy refused the image on the last copy.
+	Rejection string `json:"rejection,omitempty"`
+}
+
 type MirrorStatus struct {
 	// +optional
 	// TotalImages is the number of images that are being mirrored.
 	TotalImages int `json:"totalImages"`
+	// +optional
+	// +listType=map
+	// +listMapKey=image
+	// Images is the mirror state of each of the images.
+	Images []MirrorImage `json:"images,omitempty"`
+	// +optional
+	// ObservedGeneration is the most recent generation of the mirror that the status
+	// reflects.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// Conditions are the Ready, Progressing and Degraded conditions of the mirror.
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -184,3 +444,107 @@ type MirrorList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []Mirror `json:"items"`
 }
+
+// ImagePolicyRequirementType is the type of a requirement that the mirrored images must
+// satisfy.
+// +kubebuilder:validation:Enum=AcceptAnything;Reject;SignedBy;SigstoreSigned
+type ImagePolicyRequirementType string
+
+const (
+	// ImagePolicyAcceptAnything accepts any image.
+	ImagePolicyAcceptAnything ImagePolicyRequirementType = "AcceptAnything"
+	// ImagePolicyReject rejects every image.
+	ImagePolicyReject ImagePolicyRequirementType = "Reject"
+	// ImagePolicySignedBy requires the image to be signed by one of the GPG keys.
+	ImagePolicySignedBy ImagePolicyRequirementType = "SignedBy"
+	// ImagePolicySigstoreSigned requires the image to have a sigstore signature from the
+	// public key.
+	ImagePolicySigstoreSigned ImagePolicyRequirementType = "SigstoreSigned"
+)
+
+// ImagePolicyRequirement is a requirement that the mirrored images must satisfy.
+type ImagePolicyRequirement struct {
+	// +required
+	// Type is the type of the requirement.
+	Type ImagePolicyRequirementType `json:"type"`
+	// +optional
+	// KeyData is the ASCII armored GPG keyring for the SignedBy requirement, or the PEM
+	// encoded public key for the SigstoreSigned requirement.
+	KeyData string `json:"keyData,omitempty"`
+}
+
+// ImagePolicyScope maps a registry or repository to the requirements for its images.
+type ImagePolicyScope struct {
+	// +required
+	// Scope is a registry, a registry namespace, a repository or a repository and tag,
+	// for example quay.io, docker.io/library, docker.io/library/nginx or
+	// docker.io/library/nginx:latest.  Wildcards are supported for registry subdomains,
+	// for example *.example.com.  The most specific scope that matches an image applies.
+	Scope string `json:"scope"`
+	// +optional
+	// Lookaside is the URL of the signature storage for GPG signatures that are not
+	// served by the registry itself.
+	Lookaside string `json:"lookaside,omitempty"`
+	// +required
+	// +listType=atomic
+	// Requirements must all be satisfied by the images in the scope.
+	Requirements []ImagePolicyRequirement `json:"requirements"`
+}
+
+// ImagePolicySpec is the spec for the ImagePolicy and ClusterImagePolicy resources.
+type ImagePolicySpec struct {
+	// +optional
+	// +listType=atomic
+	// Default are the requirements for the images that do not match any of the scopes.
+	// Images that do not match any scope are accepted when no policy sets a default.
+	Default []ImagePolicyRequirement `json:"default,omitempty"`
+	// +optional
+	// +listType=atomic
+	// Scopes are the requirements for the images of specific registries and repositories.
+	Scopes []ImagePolicyScope `json:"scopes,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:validation:Required
+// +kubebuilder:resource:scope=Namespaced,shortName=ipol,singular=imagepolicy
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ImagePolicy defines the signatures that are required for the images mirrored by the
+// mirrors in its namespace.
+type ImagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ImagePolicySpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ImagePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImagePolicy `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:validation:Required
+// +kubebuilder:resource:scope=Cluster,shortName=cipol,singular=clusterimagepolicy
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterImagePolicy defines the signatures that are required for the images mirrored by
+// all of the mirrors in the cluster.
+type ClusterImagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ImagePolicySpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ClusterImagePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterImagePolicy `json:"items"`
+}
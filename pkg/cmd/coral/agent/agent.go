@@ -22,6 +22,7 @@ import (
 	"ctx.sh/coral/pkg/agent/reporter"
 
 	"ctx.sh/coral/pkg/agent/watcher"
+	"ctx.sh/coral/pkg/store"
 	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -48,6 +49,8 @@ type Agent struct {
 	KeyName                  string
 	SkipInsecureVerify       bool
 	ClientCAName             string
+	ResyncInterval           time.Duration
+	ResyncJitter             float64
 }
 
 func (a *Agent) RunE(cmd *cobra.Command, args []string) error {
@@ -76,11 +79,21 @@ func (a *Agent) RunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// The ledger is shared between the watcher and the reporter so that only images managed
+	// by coral are reported back to the controller.  The pulls are shared so that the reporter
+	// can report the images that are still being pulled or that failed to pull.
+	ledger := store.NewLedger()
+	pulls := store.NewPulls()
+
 	if err = watcher.SetupWithManager(ctx, mgr, &watcher.Options{
 		ContainerAddr:            a.ContainerdAddr,
 		MaxConcurrentReconcilers: a.MaxConcurrentReconcilers,
 		MaxConcurrentPullers:     a.MaxConcurrentPullers,
 		NodeName:                 nodeName,
+		Ledger:                   ledger,
+		Pulls:                    pulls,
+		ResyncInterval:           a.ResyncInterval,
+		ResyncJitter:             a.ResyncJitter,
 	}); err != nil {
 		log.Error(err, "unable to setup controllers")
 		os.Exit(1)
@@ -89,6 +102,8 @@ func (a *Agent) RunE(cmd *cobra.Command, args []string) error {
 	if err = reporter.SetupWithManager(ctx, mgr, &reporter.Options{
 		ContainerAddr:      a.ContainerdAddr,
 		NodeName:           nodeName,
+		Ledger:             ledger,
+		Pulls:              pulls,
 		Host:               a.Host,
 		CertDir:            a.CertDir,
 		CertName:           a.CertName,
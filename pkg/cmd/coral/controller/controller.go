@@ -17,15 +17,18 @@ package controller
 import (
 	"crypto/tls"
 	"os"
+	"time"
 
 	"ctx.sh/coral/pkg/store"
 
 	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
 	"ctx.sh/coral/pkg/controller"
 	"ctx.sh/coral/pkg/webhook"
+	"ctx.sh/coral/pkg/webhook/v1beta1/registry"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap/zapcore"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -45,6 +48,10 @@ type Controller struct {
 	SkipInsecureVerify bool
 	Namespace          string
 	LogLevel           int8
+	ResolveInterval    time.Duration
+	GateMaxWait        time.Duration
+	RegistryHost       string
+	InjectorConfig     string
 }
 
 func (c *Controller) RunE(cmd *cobra.Command, args []string) error {
@@ -52,6 +59,7 @@ func (c *Controller) RunE(cmd *cobra.Command, args []string) error {
 	_ = coralv1beta1.AddToScheme(scheme)
 	_ = corev1.AddToScheme(scheme)
 	_ = appsv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
 
 	// TODO: more configurations to mirror bind flags.
 	log := zap.New(
@@ -93,10 +101,16 @@ func (c *Controller) RunE(cmd *cobra.Command, args []string) error {
 	}
 
 	nodeRef := store.NewNodeRef()
+	reg := registry.New(&registry.Options{
+		Port: 5000,
+	})
 
 	// Set up controllers
 	if err = controller.SetupWithManager(mgr, &controller.Options{
-		NodeRef: nodeRef,
+		NodeRef:         nodeRef,
+		ResolveInterval: c.ResolveInterval,
+		GateMaxWait:     c.GateMaxWait,
+		Pruner:          reg,
 	}); err != nil {
 		log.Error(err, "unable to setup controllers")
 		os.Exit(1)
@@ -104,8 +118,10 @@ func (c *Controller) RunE(cmd *cobra.Command, args []string) error {
 
 	// Set up webhooks
 	if err = webhook.SetupWebhooksWithManager(ctx, mgr, &webhook.Options{
-		RegistryPort: 5000,
-		NodeRef:      nodeRef,
+		Registry:       reg,
+		RegistryHost:   c.RegistryHost,
+		InjectorConfig: c.InjectorConfig,
+		NodeRef:        nodeRef,
 	}); err != nil {
 		log.Error(err, "unable to setup webhooks")
 		os.Exit(1)
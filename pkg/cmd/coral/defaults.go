@@ -14,17 +14,25 @@
 
 package main
 
+import "time"
+
 const (
-	DefaultCertDir                  string = "/etc/coral/tls"
-	DefaultCACertName               string = "ca.crt"
-	DefaultCertName                 string = "tls.crt"
-	DefaultKeyName                  string = "tls.key"
-	DefaultEnableLeaderElection     bool   = false
-	DefaultSkipInsecureVerify       bool   = true
-	DefaultLogLevel                 int8   = 4
-	DefaultContainerdAddr           string = "unix:///run/containerd/containerd.sock"
-	DefaultNamespace                string = ""
-	DefaultMaxConcurrentPullers     int    = 10
-	DefaultMaxConcurrentReconcilers int    = 3
-	DefaultCoralHost                string = "https://coral-webhook-service.coral-system.svc"
+	DefaultCertDir                  string        = "/etc/coral/tls"
+	DefaultCACertName               string        = "ca.crt"
+	DefaultCertName                 string        = "tls.crt"
+	DefaultKeyName                  string        = "tls.key"
+	DefaultEnableLeaderElection     bool          = false
+	DefaultSkipInsecureVerify       bool          = true
+	DefaultLogLevel                 int8          = 4
+	DefaultContainerdAddr           string        = "unix:///run/containerd/containerd.sock"
+	DefaultNamespace                string        = ""
+	DefaultMaxConcurrentPullers     int           = 10
+	DefaultMaxConcurrentReconcilers int           = 3
+	DefaultCoralHost                string        = "https://coral-webhook-service.coral-system.svc"
+	DefaultResyncInterval           time.Duration = 10 * time.Minute
+	DefaultResolveInterval          time.Duration = 5 * time.Minute
+	DefaultResyncJitter             float64       = 0.5
+	DefaultGateMaxWait              time.Duration = 10 * time.Minute
+	DefaultRegistryHost             string        = ""
+	DefaultInjectorConfig           string        = ""
 )
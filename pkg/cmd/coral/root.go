@@ -86,6 +86,10 @@ func ControllerCommand() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(&c.SkipInsecureVerify, "skip-insecure-verify", "", DefaultSkipInsecureVerify, "skip certificate verification for the webhooks")
 	cmd.PersistentFlags().Int8VarP(&c.LogLevel, "log-level", "", DefaultLogLevel, "set the log level (integer value)")
 	cmd.PersistentFlags().StringVarP(&c.Namespace, "namespace", "n", DefaultNamespace, "limit the coral scope to a specific namespace")
+	cmd.PersistentFlags().DurationVarP(&c.ResolveInterval, "resolve-interval", "", DefaultResolveInterval, "set the interval for resolving image tags to digests, 0 disables resolution")
+	cmd.PersistentFlags().DurationVarP(&c.GateMaxWait, "gate-max-wait", "", DefaultGateMaxWait, "set the default maximum time that pods are held by the availability scheduling gate")
+	cmd.PersistentFlags().StringVarP(&c.RegistryHost, "registry-host", "", DefaultRegistryHost, "set the coral registry address that the nodes pull mirrored images from, empty disables image rewriting")
+	cmd.PersistentFlags().StringVarP(&c.InjectorConfig, "injector-config", "", DefaultInjectorConfig, "specify the injector configuration file mapping custom resources to their pod templates")
 	return cmd
 }
 
@@ -108,6 +112,8 @@ func AgentCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&a.ContainerdAddr, "containerd-addr", "A", DefaultContainerdAddr, "set the containerd address")
 	cmd.PersistentFlags().IntVarP(&a.MaxConcurrentReconcilers, "max-concurrent-reconcilers", "", DefaultMaxConcurrentReconcilers, "set the max concurrency for resource reconciliation")
 	cmd.PersistentFlags().IntVarP(&a.MaxConcurrentPullers, "max-concurrent-pullers", "", DefaultMaxConcurrentPullers, "set the max concurrency for pulling images")
+	cmd.PersistentFlags().DurationVarP(&a.ResyncInterval, "resync-interval", "", DefaultResyncInterval, "set the interval for resyncing the images on the node, 0 disables resync")
+	cmd.PersistentFlags().Float64VarP(&a.ResyncJitter, "resync-jitter", "", DefaultResyncJitter, "set the jitter factor applied to the resync interval")
 
 	return cmd
 }
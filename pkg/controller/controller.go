@@ -15,27 +15,48 @@
 package controller
 
 import (
+	"time"
+
+	"ctx.sh/coral/pkg/controller/gate"
 	"ctx.sh/coral/pkg/controller/imagesync"
 	"ctx.sh/coral/pkg/controller/mirror"
+	"ctx.sh/coral/pkg/controller/workload"
 	"ctx.sh/coral/pkg/store"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 type Options struct {
-	NodeRef *store.NodeRef
+	NodeRef         *store.NodeRef
+	ResolveInterval time.Duration
+	GateMaxWait     time.Duration
+	Pruner          mirror.Pruner
 }
 
 type Controller struct{}
 
 func SetupWithManager(mgr ctrl.Manager, opts *Options) (err error) {
 	if err = imagesync.SetupWithManager(mgr, &imagesync.Options{
-		NodeRef: opts.NodeRef,
+		NodeRef:         opts.NodeRef,
+		ResolveInterval: opts.ResolveInterval,
 	}); err != nil {
 		return err
 	}
 
 	if err = mirror.SetupWithManager(mgr, &mirror.Options{
 		Registry: "localhost:5000",
+		Pruner:   opts.Pruner,
+	}); err != nil {
+		return err
+	}
+
+	if err = workload.SetupWithManager(mgr, &workload.Options{}); err != nil {
+		return err
+	}
+
+	if err = gate.SetupWithManager(mgr, &gate.Options{
+		NodeRef:      opts.NodeRef,
+		PollInterval: gate.DefaultPollInterval,
+		MaxWait:      opts.GateMaxWait,
 	}); err != nil {
 		return err
 	}
@@ -0,0 +1,90 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gate
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// available returns true once at least the minimum number of nodes that the pod could be
+// scheduled on hold all of the images of the pod, or once the max wait has expired.
+func (c *Controller) available(ctx context.Context, pod *corev1.Pod, now time.Time) (bool, time.Duration, error) {
+	deadline := pod.CreationTimestamp.Add(timeout(ctx, pod, coralv1beta1.ImageSyncMaxWaitAnnotation, c.MaxWait))
+	if !now.Before(deadline) {
+		ctrl.LoggerFrom(ctx).V(2).Info("availability gate timed out", "pod", pod.Name, "namespace", pod.Namespace)
+		return true, 0, nil
+	}
+
+	matcher, err := util.NewPodNodeMatcher(&pod.Spec)
+	if err != nil {
+		// The scheduler will reject the pod for the same reason, there is nothing to wait for.
+		return true, 0, nil //nolint:nilerr
+	}
+
+	var nodes corev1.NodeList
+	if err := c.List(ctx, &nodes); err != nil {
+		return false, 0, err
+	}
+
+	images := podImages(pod)
+	minNodes := MinNodes(pod)
+
+	count := 0
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if matcher.Matches(node) && c.holds(node.Name, images) {
+			count++
+		}
+
+		if count >= minNodes {
+			return true, 0, nil
+		}
+	}
+
+	return false, c.wait(deadline, now), nil
+}
+
+// holds returns true if the node has all of the images available.
+func (c *Controller) holds(node string, images []string) bool {
+	if c.NodeRef == nil {
+		return false
+	}
+
+	for _, img := range images {
+		if !c.NodeRef.HasImage(node, img) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MinNodes returns the minimum number of nodes that must hold the images of the pod from
+// the min-nodes annotation.  Missing or invalid values require a single node.
+func MinNodes(pod *corev1.Pod) int {
+	n, err := strconv.Atoi(pod.Annotations[coralv1beta1.ImageSyncMinNodesAnnotation])
+	if err != nil || n < 1 {
+		return 1
+	}
+
+	return n
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gate
+
+import (
+	"context"
+	"time"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/store"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// DefaultPollInterval is the interval at which gated pods are re-evaluated.
+	DefaultPollInterval = 10 * time.Second
+	// DefaultWarmTimeout is the maximum time that a pod is held by the warming gate.
+	DefaultWarmTimeout = 5 * time.Minute
+	// DefaultMaxWait is the maximum time that a pod is held by the availability gate.
+	DefaultMaxWait = 10 * time.Minute
+)
+
+type Options struct {
+	NodeRef      *store.NodeRef
+	PollInterval time.Duration
+	MaxWait      time.Duration
+}
+
+// Controller removes the coral scheduling gates from pods once the images of the pod have
+// been synced or the gate times out.
+type Controller struct {
+	NodeRef      *store.NodeRef
+	PollInterval time.Duration
+	MaxWait      time.Duration
+	client.Client
+}
+
+func SetupWithManager(mgr ctrl.Manager, opts *Options) error {
+	c := &Controller{
+		Client:       mgr.GetClient(),
+		NodeRef:      opts.NodeRef,
+		PollInterval: opts.PollInterval,
+		MaxWait:      opts.MaxWait,
+	}
+
+	if c.PollInterval == 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+
+	if c.MaxWait == 0 {
+		c.MaxWait = DefaultMaxWait
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("gate").
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.NewPredicateFuncs(gated))).
+		Complete(c)
+}
+
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	logger.V(4).Info("reconciling gated pod", "request", req)
+
+	pod := new(corev1.Pod)
+	if err := c.Get(ctx, req.NamespacedName, pod); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !gated(pod) || !pod.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	requeue := time.Duration(0)
+	gates := make([]corev1.PodSchedulingGate, 0, len(pod.Spec.SchedulingGates))
+
+	for _, gate := range pod.Spec.SchedulingGates {
+		var (
+			released bool
+			wait     time.Duration
+			err      error
+		)
+
+		switch gate.Name {
+		case coralv1beta1.ImageSyncWarmingGate:
+			released, wait, err = c.warmed(ctx, pod, now)
+		case coralv1beta1.ImageSyncAvailableGate:
+			released, wait, err = c.available(ctx, pod, now)
+		default:
+			gates = append(gates, gate)
+			continue
+		}
+
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if released {
+			logger.V(2).Info("releasing scheduling gate", "pod", req.NamespacedName, "gate", gate.Name)
+			continue
+		}
+
+		gates = append(gates, gate)
+		if requeue == 0 || wait < requeue {
+			requeue = wait
+		}
+	}
+
+	if len(gates) != len(pod.Spec.SchedulingGates) {
+		// Only the scheduling gates are patched, the rest of the pod spec is immutable and
+		// an update would be rejected if any other field changed since the pod was read.
+		patch := client.MergeFrom(pod.DeepCopy())
+		pod.Spec.SchedulingGates = gates
+		if err := c.Patch(ctx, pod, patch); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// wait returns the time to wait before the gate is evaluated again, which is the poll
+// interval or the time left until the deadline if it is sooner.
+func (c *Controller) wait(deadline, now time.Time) time.Duration {
+	if left := deadline.Sub(now); left < c.PollInterval {
+		return left
+	}
+
+	return c.PollInterval
+}
+
+// gated returns true if the pod is held by any of the coral scheduling gates.
+func gated(obj client.Object) bool {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+
+	for _, gate := range pod.Spec.SchedulingGates {
+		switch gate.Name {
+		case coralv1beta1.ImageSyncWarmingGate, coralv1beta1.ImageSyncAvailableGate:
+			return true
+		}
+	}
+
+	return false
+}
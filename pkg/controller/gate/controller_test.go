@@ -0,0 +1,159 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/mock"
+	"ctx.sh/coral/pkg/store"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+type ControllerTestSuite struct {
+	client *mock.Client
+	suite.Suite
+}
+
+func (s *ControllerTestSuite) SetupTest() {
+	logger := zap.New(zap.UseDevMode(true), zap.Level(zapcore.Level(-8)))
+	log.SetLogger(logger)
+
+	s.client = mock.NewClient().
+		WithLogger(logger).
+		WithFixtureDirectory(filepath.Join("..", "..", "..", "fixtures"))
+
+	s.client.ApplyFixtureOrDie("gate-controller.yaml")
+}
+
+func (s *ControllerTestSuite) TearDownTest() {
+	s.client.Reset()
+}
+
+func TestControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ControllerTestSuite))
+}
+
+func (s *ControllerTestSuite) TestReconcile_warming() {
+	tests := []struct {
+		name     string
+		pod      string
+		gates    []string
+		requeued bool
+	}{
+		{
+			name:  "released when the images are available",
+			pod:   "warm-ready",
+			gates: []string{"example.com/other"},
+		},
+		{
+			name:     "held while images are pending",
+			pod:      "warm-pending",
+			gates:    []string{"imagesync.coral.ctx.sh/warming"},
+			requeued: true,
+		},
+		{
+			name:  "released when the original images of rewritten and pinned containers are available",
+			pod:   "warm-rewritten",
+			gates: []string{},
+		},
+		{
+			name:  "released when the timeout expires",
+			pod:   "warm-expired",
+			gates: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			ctx := context.Background()
+			c := &Controller{
+				Client:       s.client,
+				PollInterval: DefaultPollInterval,
+			}
+
+			key := types.NamespacedName{Namespace: "default", Name: tt.pod}
+			result, err := c.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			s.Require().NoError(err)
+
+			if tt.requeued {
+				s.Equal(DefaultPollInterval, result.RequeueAfter)
+			} else {
+				s.Equal(time.Duration(0), result.RequeueAfter)
+			}
+
+			var pod corev1.Pod
+			s.Require().NoError(s.client.Get(ctx, key, &pod))
+
+			gates := make([]string, 0, len(pod.Spec.SchedulingGates))
+			for _, gate := range pod.Spec.SchedulingGates {
+				gates = append(gates, gate.Name)
+			}
+			s.Equal(tt.gates, gates)
+		})
+	}
+}
+
+func (s *ControllerTestSuite) TestReconcile_available() {
+	ctx := context.Background()
+	image := "docker.io/pytorch/pytorch:2.4.0"
+
+	nodeRef := store.NewNodeRef()
+	c := &Controller{
+		Client:       s.client,
+		NodeRef:      nodeRef,
+		PollInterval: DefaultPollInterval,
+		MaxWait:      DefaultMaxWait,
+	}
+
+	key := types.NamespacedName{Namespace: "default", Name: "available"}
+	reconcile := func() []corev1.PodSchedulingGate {
+		_, err := c.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		s.Require().NoError(err)
+
+		var pod corev1.Pod
+		s.Require().NoError(s.client.Get(ctx, key, &pod))
+		return pod.Spec.SchedulingGates
+	}
+
+	// The image is on an eligible node and on a node that the pod can not be scheduled on.
+	nodeRef.AddImages("node1", []string{image})
+	nodeRef.AddImages("node3", []string{image})
+	s.Len(reconcile(), 1)
+
+	nodeRef.AddImages("node2", []string{image})
+	s.Empty(reconcile())
+}
+
+func (s *ControllerTestSuite) TestMinNodes() {
+	pod := &corev1.Pod{}
+	s.Equal(1, MinNodes(pod))
+
+	pod.Annotations = map[string]string{coralv1beta1.ImageSyncMinNodesAnnotation: "3"}
+	s.Equal(3, MinNodes(pod))
+
+	pod.Annotations[coralv1beta1.ImageSyncMinNodesAnnotation] = "invalid"
+	s.Equal(1, MinNodes(pod))
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gate
+
+import (
+	"context"
+	"time"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// warmed returns true once every image of the pod has been synced to all of the nodes
+// targeted by an imagesync that lists it, or once the warm timeout has expired.  Images that
+// are not listed in any imagesync hold the pod until the timeout.
+func (c *Controller) warmed(ctx context.Context, pod *corev1.Pod, now time.Time) (bool, time.Duration, error) {
+	deadline := pod.CreationTimestamp.Add(timeout(ctx, pod, coralv1beta1.ImageSyncWarmTimeoutAnnotation, DefaultWarmTimeout))
+	if !now.Before(deadline) {
+		ctrl.LoggerFrom(ctx).V(2).Info("warming gate timed out", "pod", pod.Name, "namespace", pod.Namespace)
+		return true, 0, nil
+	}
+
+	isyncs, err := util.ListImageSyncsInScope(ctx, c.Client, pod.Namespace)
+	if err != nil {
+		return false, 0, err
+	}
+
+	warm := make(map[string]bool)
+	for _, isync := range isyncs {
+		status := isync.GetImageSyncStatus()
+		if status.ObservedGeneration != isync.GetGeneration() {
+			continue
+		}
+
+		for _, img := range status.Images {
+			if img.Available > 0 && img.Pending == 0 {
+				warm[img.Image] = true
+			}
+		}
+	}
+
+	for _, img := range podImages(pod) {
+		if !warm[img] {
+			return false, c.wait(deadline, now), nil
+		}
+	}
+
+	return true, 0, nil
+}
+
+// podImages returns the normalized images of the containers and init containers.  Images that
+// the injector rewrote to the coral registry or pinned to a digest are returned as the image
+// that the container originally referenced, which is the image that the imagesyncs list.
+func podImages(pod *corev1.Pod) []string {
+	original := util.ParseContainerImages(pod.Annotations[coralv1beta1.ImageSyncOriginalImagesAnnotation])
+
+	images := make([]string, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			image := container.Image
+			if o, ok := original[container.Name]; ok {
+				image = o
+			}
+
+			if ref, err := util.ParseReference(image); err == nil {
+				images = append(images, ref.String())
+			}
+		}
+	}
+
+	return images
+}
+
+// timeout returns the duration from the annotation, or the default if it is not set or
+// can not be parsed.
+func timeout(ctx context.Context, pod *corev1.Pod, annotation string, def time.Duration) time.Duration {
+	value, ok := pod.Annotations[annotation]
+	if !ok {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		ctrl.LoggerFrom(ctx).Info("invalid timeout, using the default", "annotation", annotation, "value", value)
+		return def
+	}
+
+	return d
+}
@@ -35,20 +35,33 @@ import (
 
 type Options struct {
 	NodeRef *store.NodeRef
+	// ResolveInterval is the interval at which the image tags are resolved to digests.  A
+	// zero value disables digest resolution.
+	ResolveInterval time.Duration
 }
 
 type Controller struct {
 	Cache    cache.Cache
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// Cluster is true when the controller manages clusterimagesyncs.
+	Cluster bool
 	client.Client
 }
 
 func SetupWithManager(mgr ctrl.Manager, opts *Options) error {
-	if err := mgr.Add(NewStatusUpdater(mgr.GetClient(), opts.NodeRef)); err != nil {
+	digests := store.NewDigests()
+
+	if err := mgr.Add(NewStatusUpdater(mgr.GetClient(), opts.NodeRef).WithDigests(digests)); err != nil {
 		return err
 	}
 
+	if opts.ResolveInterval > 0 {
+		if err := mgr.Add(NewResolver(mgr.GetClient(), digests, opts.ResolveInterval)); err != nil {
+			return err
+		}
+	}
+
 	c := &Controller{
 		Cache:    mgr.GetCache(),
 		Client:   mgr.GetClient(),
@@ -56,14 +69,31 @@ func SetupWithManager(mgr ctrl.Manager, opts *Options) error {
 		Recorder: mgr.GetEventRecorderFor("imagesync-controller"),
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&coralv1beta1.ImageSync{}).
 		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		Complete(c)
+		Complete(c); err != nil {
+		return err
+	}
+
+	cc := &Controller{
+		Cache:    mgr.GetCache(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("clusterimagesync-controller"),
+		Cluster:  true,
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&coralv1beta1.ClusterImageSync{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(cc)
 }
 
 // +kubebuilder:rbac:groups=coral.ctx.sh,resources=imagesyncs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coral.ctx.sh,resources=imagesyncs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=coral.ctx.sh,resources=clusterimagesyncs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=coral.ctx.sh,resources=clusterimagesyncs/status,verbs=get;update;patch
 
 func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -73,6 +103,7 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	observer := StateObserver{
 		Client:  c.Client,
 		Request: req,
+		Cluster: c.Cluster,
 	}
 
 	err := observer.observe(ctx, observed)
@@ -98,14 +129,14 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{Requeue: true}, c.addFinalizer(ctx, isync)
 	}
 
-	if !isync.DeletionTimestamp.IsZero() {
+	if !isync.GetDeletionTimestamp().IsZero() {
 		return ctrl.Result{}, c.removeFinalizer(ctx, isync)
 	}
 
 	return ctrl.Result{}, nil
 }
 
-func (c *Controller) addFinalizer(ctx context.Context, isync *coralv1beta1.ImageSync) error {
+func (c *Controller) addFinalizer(ctx context.Context, isync coralv1beta1.ImageSyncObject) error {
 	controllerutil.AddFinalizer(isync, coralv1beta1.ImageSyncFinalizer)
 	if err := c.Update(ctx, isync); err != nil {
 		return err
@@ -114,7 +145,7 @@ func (c *Controller) addFinalizer(ctx context.Context, isync *coralv1beta1.Image
 	return nil
 }
 
-func (c *Controller) removeFinalizer(ctx context.Context, isync *coralv1beta1.ImageSync) error {
+func (c *Controller) removeFinalizer(ctx context.Context, isync coralv1beta1.ImageSyncObject) error {
 	if controllerutil.ContainsFinalizer(isync, coralv1beta1.ImageSyncFinalizer) {
 		if err := c.finalize(ctx, isync); err != nil {
 			return err
@@ -129,7 +160,7 @@ func (c *Controller) removeFinalizer(ctx context.Context, isync *coralv1beta1.Im
 	return nil
 }
 
-func (c *Controller) finalize(ctx context.Context, isync *coralv1beta1.ImageSync) error {
+func (c *Controller) finalize(ctx context.Context, isync coralv1beta1.ImageSyncObject) error {
 	// TODO: I'm not currently using the finalizers, but it does trigger an update event
 	//   with the deletion time set that the agents can catch for cleanup.
 	return nil
@@ -19,12 +19,13 @@ import (
 	"time"
 
 	coralctxshv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type ObservedState struct {
-	ImageSync   *coralctxshv1beta1.ImageSync
+	ImageSync   coralctxshv1beta1.ImageSyncObject
 	ObserveTime time.Time
 }
 
@@ -38,6 +39,8 @@ func NewObservedState() *ObservedState {
 type StateObserver struct {
 	Client  client.Client
 	Request ctrl.Request
+	// Cluster is true when the request is for a clusterimagesync.
+	Cluster bool
 }
 
 func (o *StateObserver) observe(ctx context.Context, observed *ObservedState) error {
@@ -56,8 +59,8 @@ func (o *StateObserver) observe(ctx context.Context, observed *ObservedState) er
 	return nil
 }
 
-func (o *StateObserver) observerImageSync(ctx context.Context) (*coralctxshv1beta1.ImageSync, error) {
-	observedImageSync := new(coralctxshv1beta1.ImageSync)
+func (o *StateObserver) observerImageSync(ctx context.Context) (coralctxshv1beta1.ImageSyncObject, error) {
+	observedImageSync := util.NewImageSyncObject(o.Cluster)
 	err := o.Client.Get(ctx, o.Request.NamespacedName, observedImageSync)
 	if err != nil {
 		if client.IgnoreNotFound(err) != nil {
@@ -66,5 +69,5 @@ func (o *StateObserver) observerImageSync(ctx context.Context) (*coralctxshv1bet
 		return nil, nil
 	}
 
-	return observedImageSync.DeepCopy(), nil
+	return observedImageSync.DeepCopyObject().(coralctxshv1beta1.ImageSyncObject), nil
 }
@@ -109,7 +109,7 @@ func (s *ObserveTestSuite) TestStateObserver_observerImageSync() {
 					s.Nil(imagesync)
 				} else {
 					s.NotNil(imagesync)
-					s.Equal(tt.imageSyncName, imagesync.Name)
+					s.Equal(tt.imageSyncName, imagesync.GetName())
 				}
 			}
 		})
@@ -162,7 +162,7 @@ func (s *ObserveTestSuite) TestStateObserver_observe() {
 					s.Nil(observed.ImageSync)
 				} else {
 					s.NotNil(observed.ImageSync)
-					s.Equal(tt.imageSyncName, observed.ImageSync.Name)
+					s.Equal(tt.imageSyncName, observed.ImageSync.GetName())
 				}
 			}
 		})
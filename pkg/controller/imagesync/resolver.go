@@ -0,0 +1,181 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagesync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/store"
+	"ctx.sh/coral/pkg/util"
+	utilauth "ctx.sh/coral/pkg/util/auth"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/types"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ResolveFunc resolves the digest that an image tag currently points to.
+type ResolveFunc func(ctx context.Context, image string, auth *utilauth.Auth) (string, error)
+
+// Resolver is a process that runs in the background and periodically resolves the tags of
+// the imagesync images to their current digests so that tag moves can be detected.
+type Resolver struct {
+	digests  *store.Digests
+	interval time.Duration
+	resolve  ResolveFunc
+	client.Client
+}
+
+// NewResolver creates a new digest resolver.
+func NewResolver(c client.Client, digests *store.Digests, interval time.Duration) *Resolver {
+	return &Resolver{
+		Client:   c,
+		digests:  digests,
+		interval: interval,
+		resolve:  resolveDigest,
+	}
+}
+
+// WithResolveFunc overrides the function used to resolve the digests.
+func (r *Resolver) WithResolveFunc(fn ResolveFunc) *Resolver {
+	r.resolve = fn
+	return r
+}
+
+// NeedLeaderElection returns true to indicate that this runnable should run
+// when the controller manager is the leader.
+func (r *Resolver) NeedLeaderElection() bool {
+	return true
+}
+
+// Start starts the resolver process.
+func (r *Resolver) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(4).Info("starting imagesync digest resolver")
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.run(ctx); err != nil {
+			log.Error(err, "failed to resolve image digests")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Resolver) run(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	isyncs, err := util.ListImageSyncs(ctx, r.Client)
+	if err != nil {
+		return err
+	}
+
+	for _, isync := range isyncs {
+		if !isync.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		secrets, err := r.pullSecrets(ctx, isync)
+		if err != nil {
+			log.Error(err, "failed to get pull secrets", "name", isync.GetName(), "namespace", isync.GetNamespace())
+			continue
+		}
+
+		auth, err := utilauth.NewAuth(secrets)
+		if err != nil {
+			log.Error(err, "failed to create auth", "name", isync.GetName(), "namespace", isync.GetNamespace())
+			continue
+		}
+
+		for _, image := range isync.GetImages() {
+			ref, err := util.ParseReference(image)
+			// Images referenced by digest can't move.
+			if err != nil || ref.IsDigested() {
+				continue
+			}
+
+			fqn := ref.String()
+
+			digest, err := r.resolve(ctx, fqn, auth)
+			if err != nil {
+				log.Error(err, "failed to resolve digest", "image", fqn)
+				continue
+			}
+
+			if previous := r.digests.Get(fqn); previous != "" && previous != digest {
+				log.V(2).Info("image tag has moved", "image", fqn, "previous", previous, "digest", digest)
+			}
+
+			r.digests.Set(fqn, digest)
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) pullSecrets(ctx context.Context, isync coralv1beta1.ImageSyncObject) ([]corev1.Secret, error) {
+	refs := isync.GetPullSecrets()
+	secrets := make([]corev1.Secret, 0, len(refs))
+	for _, ref := range refs {
+		var secret corev1.Secret
+		if err := r.Get(ctx, ref, &secret); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
+
+// resolveDigest resolves the manifest digest of the image from the registry.
+func resolveDigest(ctx context.Context, image string, auth *utilauth.Auth) (string, error) {
+	ref, err := docker.ParseReference("//" + image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse reference: %w", err)
+	}
+
+	sys := &types.SystemContext{}
+	if configs := auth.Lookup(image); len(configs) > 0 {
+		sys.DockerAuthConfig = &types.DockerAuthConfig{
+			Username: configs[0].Username,
+			Password: configs[0].Password,
+		}
+	}
+
+	digest, err := docker.GetDigest(ctx, sys, ref)
+	if err != nil {
+		return "", err
+	}
+
+	return digest.String(), nil
+}
+
+var _ manager.Runnable = &Resolver{}
+var _ manager.LeaderElectionRunnable = &Resolver{}
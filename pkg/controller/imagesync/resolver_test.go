@@ -0,0 +1,74 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagesync
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ctx.sh/coral/pkg/mock"
+	"ctx.sh/coral/pkg/store"
+	utilauth "ctx.sh/coral/pkg/util/auth"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+type ResolverTestSuite struct {
+	client *mock.Client
+	suite.Suite
+}
+
+func (s *ResolverTestSuite) SetupTest() {
+	logger := zap.New(zap.UseDevMode(true), zap.Level(zapcore.Level(-8)))
+	log.SetLogger(logger)
+
+	s.client = mock.NewClient().
+		WithLogger(logger).
+		WithFixtureDirectory(filepath.Join("..", "..", "..", "fixtures"))
+
+	s.client.ApplyFixtureOrDie("imagesync-controller.yaml")
+}
+
+func (s *ResolverTestSuite) TearDownTest() {
+	s.client.Reset()
+}
+
+func TestResolverTestSuite(t *testing.T) {
+	suite.Run(t, new(ResolverTestSuite))
+}
+
+func (s *ResolverTestSuite) TestResolver_run() {
+	digests := store.NewDigests()
+	resolver := NewResolver(s.client, digests, time.Minute).
+		WithResolveFunc(func(ctx context.Context, image string, auth *utilauth.Auth) (string, error) {
+			switch image {
+			case "docker.io/library/nginx:latest":
+				return "sha256:nginx", nil
+			default:
+				return "", errors.New("manifest unknown")
+			}
+		})
+
+	err := resolver.run(context.Background())
+	s.NoError(err)
+
+	s.Equal("sha256:nginx", digests.Get("docker.io/library/nginx:latest"))
+	s.Empty(digests.Get("docker.io/library/redis:latest"))
+}
@@ -16,14 +16,17 @@ package imagesync
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"ctx.sh/coral/pkg/store"
 	"ctx.sh/coral/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -43,6 +46,7 @@ type StatusCheck map[types.NamespacedName]coralv1beta1.ImageSyncCondition
 // imagesync on the required nodes.
 type StatusUpdater struct {
 	nodeRef  *store.NodeRef
+	digests  *store.Digests
 	stopCh   chan struct{}
 	stopOnce sync.Once
 	client.Client
@@ -57,6 +61,13 @@ func NewStatusUpdater(c client.Client, ref *store.NodeRef) *StatusUpdater {
 	}
 }
 
+// WithDigests sets the store of resolved image digests.  When a digest has been resolved for
+// an image, nodes are only considered available when they have that digest.
+func (su *StatusUpdater) WithDigests(digests *store.Digests) *StatusUpdater {
+	su.digests = digests
+	return su
+}
+
 // NeedLeaderElection returns true to indicate that this runnable should run
 // when the controller manager is the leader.
 func (su *StatusUpdater) NeedLeaderElection() bool {
@@ -96,12 +107,12 @@ func (su *StatusUpdater) update(ctx context.Context) error { // nolint:gocognit
 
 	log := ctrl.LoggerFrom(ctx)
 
-	var isyncs coralv1beta1.ImageSyncList
-	if err := su.List(ctx, &isyncs); err != nil {
+	isyncs, err := util.ListImageSyncs(ctx, su.Client)
+	if err != nil {
 		return err
 	}
 
-	if len(isyncs.Items) == 0 {
+	if len(isyncs) == 0 {
 		return nil
 	}
 
@@ -110,59 +121,73 @@ func (su *StatusUpdater) update(ctx context.Context) error { // nolint:gocognit
 		return err
 	}
 
-	for _, item := range isyncs.Items {
-		isync := item.DeepCopy()
+	for _, isync := range isyncs {
 		nlog := log.WithValues("name", isync.GetName(), "namespace", isync.GetNamespace())
 
-		filteredNodes := su.filterNodes(nodes.Items, isync.Spec.NodeSelector)
+		filteredNodes, err := su.filterNodes(nodes.Items, isync)
 
 		status := coralv1beta1.ImageSyncStatus{
 			TotalNodes:  len(filteredNodes),
-			TotalImages: len(isync.Spec.Images),
+			TotalImages: len(isync.GetImages()),
 			Condition: coralv1beta1.ImageSyncCondition{
 				Available: 0,
 				Pending:   0,
 			},
+			ObservedGeneration: isync.GetGeneration(),
+			Conditions:         isync.GetImageSyncStatus().Conditions,
 		}
 
-		if len(filteredNodes) == 0 {
-			nlog.V(4).Info("no nodes match the imagesync node selector")
-			return su.updateStatus(ctx, isync, status)
+		if err != nil {
+			nlog.Error(err, "invalid imagesync node selector")
+			setInvalidConditions(isync, &status, fmt.Sprintf("invalid node selector: %s", err))
+			if err := su.updateStatus(ctx, isync, status); err != nil {
+				nlog.Error(err, "failed to update imagesync status")
+			}
+			continue
 		}
 
-		available := make(map[string]int)
-		for _, img := range isync.Spec.Images {
-			fqn := util.GetImageQualifiedName(util.DefaultSearchRegistry, img)
-			available[fqn] = 0
+		if len(filteredNodes) == 0 {
+			nlog.V(4).Info("no nodes match the imagesync node selector")
+			setConditions(isync, &status)
+			if err := su.updateStatus(ctx, isync, status); err != nil {
+				nlog.Error(err, "failed to update imagesync status")
+			}
+			continue
 		}
 
-		images := make([]coralv1beta1.ImageSyncImage, 0)
-		for _, image := range isync.Spec.Images {
-			fqn := util.GetImageQualifiedName(util.DefaultSearchRegistry, image)
-			for _, node := range filteredNodes {
-				if su.nodeRef.HasImage(node.Name, fqn) {
-					available[fqn]++
-				}
+		refs := make([]util.Reference, 0, len(isync.GetImages()))
+		for _, img := range isync.GetImages() {
+			ref, err := util.ParseReference(img)
+			if err != nil {
+				nlog.Error(err, "skipping invalid image")
+				continue
 			}
-
-			images = append(images, coralv1beta1.ImageSyncImage{
-				Image:     fqn,
-				Available: available[fqn],
-				Pending:   len(filteredNodes) - available[fqn],
-			})
+			refs = append(refs, ref)
 		}
 
-		// Get the number of nodes with all images available.
+		// Get the number of nodes with all images available and pinned.  This is only an
+		// approximation as the counts are per image and not per node.  Failures are tracked
+		// per node so a node is only counted once no matter how many images failed.
 		minNodes := len(filteredNodes)
-		for _, count := range available {
-			minNodes = min(minNodes, count)
+		minPinned := len(filteredNodes)
+		failedNodes := make(map[string]bool)
+
+		images := make([]coralv1beta1.ImageSyncImage, 0, len(refs))
+		for _, ref := range refs {
+			image := su.imageStatus(isync, ref, filteredNodes, failedNodes)
+			minNodes = min(minNodes, image.Available)
+			minPinned = min(minPinned, image.Pinned)
+			images = append(images, image)
 		}
 
 		status.Images = images
 		status.Condition = coralv1beta1.ImageSyncCondition{
 			Available: minNodes,
-			Pending:   len(filteredNodes) - minNodes,
+			Pending:   max(0, len(filteredNodes)-minNodes-len(failedNodes)),
+			Failed:    len(failedNodes),
+			Pinned:    minPinned,
 		}
+		setConditions(isync, &status)
 
 		nlog.V(5).Info("updating imagesync status", "status", status)
 		if err := su.updateStatus(ctx, isync, status); err != nil {
@@ -173,38 +198,195 @@ func (su *StatusUpdater) update(ctx context.Context) error { // nolint:gocognit
 	return nil
 }
 
-func (su *StatusUpdater) updateStatus(ctx context.Context, isync *coralv1beta1.ImageSync, status coralv1beta1.ImageSyncStatus) error {
-	if !reflect.DeepEqual(isync.Status, status) {
-		status.DeepCopyInto(&isync.Status)
-		isync.Status.LastUpdated = metav1.Now()
-		return su.Client.Status().Update(ctx, isync)
+// setConditions sets the Ready, Progressing and Degraded conditions from the node counts
+// in the status.
+func setConditions(isync coralv1beta1.ImageSyncObject, status *coralv1beta1.ImageSyncStatus) {
+	conditions := make([]metav1.Condition, len(status.Conditions))
+	copy(conditions, status.Conditions)
+
+	generation := isync.GetGeneration()
+	set := func(conditionType string, cstatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             cstatus,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		})
 	}
 
-	return nil
+	total := status.TotalNodes
+	available := status.Condition.Available
+	failed := status.Condition.Failed
+
+	switch {
+	case total == 0:
+		set(coralv1beta1.ConditionReady, metav1.ConditionTrue, coralv1beta1.ReasonNoMatchingNodes, "no nodes match the node selector")
+		set(coralv1beta1.ConditionProgressing, metav1.ConditionFalse, coralv1beta1.ReasonNoMatchingNodes, "no nodes match the node selector")
+	case available == total:
+		message := fmt.Sprintf("all images are available on %d nodes", total)
+		set(coralv1beta1.ConditionReady, metav1.ConditionTrue, coralv1beta1.ReasonAvailable, message)
+		set(coralv1beta1.ConditionProgressing, metav1.ConditionFalse, coralv1beta1.ReasonAvailable, message)
+	default:
+		message := fmt.Sprintf("all images are available on %d of %d nodes", available, total)
+		reason := coralv1beta1.ReasonPending
+		if status.Condition.Pending == 0 {
+			reason = coralv1beta1.ReasonPullFailed
+		}
+		set(coralv1beta1.ConditionReady, metav1.ConditionFalse, reason, message)
+
+		if status.Condition.Pending > 0 {
+			set(coralv1beta1.ConditionProgressing, metav1.ConditionTrue, coralv1beta1.ReasonPending, message)
+		} else {
+			set(coralv1beta1.ConditionProgressing, metav1.ConditionFalse, coralv1beta1.ReasonPullFailed, message)
+		}
+	}
+
+	if failed > 0 {
+		set(coralv1beta1.ConditionDegraded, metav1.ConditionTrue, coralv1beta1.ReasonPullFailed, failureMessage(status.Images))
+	} else {
+		set(coralv1beta1.ConditionDegraded, metav1.ConditionFalse, coralv1beta1.ReasonAsExpected, "no images failed to pull")
+	}
+
+	status.Conditions = conditions
 }
 
-func (su *StatusUpdater) filterNodes(nodes []corev1.Node, selectors []coralv1beta1.NodeSelector) []corev1.Node {
-	var filtered []corev1.Node
+// setInvalidConditions marks the imagesync as degraded when its nodes can not be selected.
+func setInvalidConditions(isync coralv1beta1.ImageSyncObject, status *coralv1beta1.ImageSyncStatus, message string) {
+	conditions := make([]metav1.Condition, len(status.Conditions))
+	copy(conditions, status.Conditions)
+
+	for _, c := range []struct {
+		conditionType string
+		status        metav1.ConditionStatus
+	}{
+		{coralv1beta1.ConditionReady, metav1.ConditionFalse},
+		{coralv1beta1.ConditionProgressing, metav1.ConditionFalse},
+		{coralv1beta1.ConditionDegraded, metav1.ConditionTrue},
+	} {
+		meta.SetStatusCondition(&conditions, metav1.Condition{
+			Type:               c.conditionType,
+			Status:             c.status,
+			ObservedGeneration: isync.GetGeneration(),
+			Reason:             coralv1beta1.ReasonInvalidNodeSelector,
+			Message:            message,
+		})
+	}
+
+	status.Conditions = conditions
+}
+
+// failureMessage summarizes the failures for all of the images.
+func failureMessage(images []coralv1beta1.ImageSyncImage) string {
+	parts := make([]string, 0)
+	for _, image := range images {
+		for _, failure := range image.Failures {
+			parts = append(parts, fmt.Sprintf("%s failed on %d nodes: %s", image.Image, failure.Nodes, failure.Reason))
+		}
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// imageStatus returns the status of a single image across the nodes.  Nodes that failed to
+// pull the image are added to failedNodes.
+func (su *StatusUpdater) imageStatus(
+	isync coralv1beta1.ImageSyncObject,
+	ref util.Reference,
+	nodes []corev1.Node,
+	failedNodes map[string]bool,
+) coralv1beta1.ImageSyncImage {
+	fqn := ref.String()
+	image := coralv1beta1.ImageSyncImage{
+		Image:  fqn,
+		Digest: su.digest(fqn),
+	}
+
+	reasons := make(map[string]int)
 	for _, node := range nodes {
-		if su.matches(node.Labels, selectors) {
-			filtered = append(filtered, node)
+		if su.hasImage(node.Name, ref, image.Digest) {
+			image.Available++
+		} else if reason, failed := su.nodeRef.Failure(node.Name, fqn); failed {
+			image.Failed++
+			reasons[reason]++
+			failedNodes[node.Name] = true
+		}
+
+		if isync.GetPin() && su.nodeRef.IsPinned(node.Name, fqn) {
+			image.Pinned++
 		}
 	}
 
-	return filtered
+	image.Pending = len(nodes) - image.Available - image.Failed
+	image.Failures = failures(reasons)
+
+	return image
 }
 
-func (su *StatusUpdater) matches(l map[string]string, selector []coralv1beta1.NodeSelector) bool {
-	ls := labels.NewSelector()
-	for _, v := range selector {
-		req, err := labels.NewRequirement(v.Key, v.Operator, v.Values)
-		if err != nil {
-			return false
+// failures returns the failure reasons sorted by reason.
+func failures(reasons map[string]int) []coralv1beta1.ImageSyncFailure {
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	out := make([]coralv1beta1.ImageSyncFailure, 0, len(reasons))
+	for reason, nodes := range reasons {
+		out = append(out, coralv1beta1.ImageSyncFailure{
+			Reason: reason,
+			Nodes:  nodes,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Reason < out[j].Reason
+	})
+
+	return out
+}
+
+func (su *StatusUpdater) digest(fqn string) string {
+	if su.digests == nil {
+		return ""
+	}
+
+	return su.digests.Get(fqn)
+}
+
+// hasImage returns true if the node has the image and, if the digest is known, the image at
+// the current digest.
+func (su *StatusUpdater) hasImage(node string, ref util.Reference, digest string) bool {
+	if !su.nodeRef.HasImage(node, ref.String()) {
+		return false
+	}
+
+	return digest == "" || ref.IsDigested() || su.nodeRef.HasImage(node, ref.WithDigest(digest))
+}
+
+func (su *StatusUpdater) updateStatus(ctx context.Context, isync coralv1beta1.ImageSyncObject, status coralv1beta1.ImageSyncStatus) error {
+	current := isync.GetImageSyncStatus()
+	if !reflect.DeepEqual(*current, status) {
+		status.DeepCopyInto(current)
+		current.LastUpdated = metav1.Now()
+		return su.Client.Status().Update(ctx, isync)
+	}
+
+	return nil
+}
+
+func (su *StatusUpdater) filterNodes(nodes []corev1.Node, isync coralv1beta1.ImageSyncObject) ([]corev1.Node, error) {
+	matcher, err := util.NewNodeMatcher(isync)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []corev1.Node
+	for i := range nodes {
+		if matcher.Matches(&nodes[i]) {
+			filtered = append(filtered, nodes[i])
 		}
-		ls = ls.Add(*req)
 	}
 
-	return ls.Matches(labels.Set(l))
+	return filtered, nil
 }
 
 var _ manager.Runnable = &StatusUpdater{}
@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap/zapcore"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
@@ -82,6 +83,7 @@ func (s *StatusUpdaterTestSuite) TestStatusUpdater_filterNodes() {
 		nodes     []corev1.Node
 		selectors []coralv1beta1.NodeSelector
 		expected  int
+		wantErr   bool
 	}{
 		{
 			name: "no selectors - all nodes match",
@@ -130,69 +132,14 @@ func (s *StatusUpdaterTestSuite) TestStatusUpdater_filterNodes() {
 			},
 			expected: 0,
 		},
-	}
-
-	for _, tt := range tests {
-		s.Run(tt.name, func() {
-			updater := &StatusUpdater{}
-			filtered := updater.filterNodes(tt.nodes, tt.selectors)
-			s.Equal(tt.expected, len(filtered))
-		})
-	}
-}
-
-func (s *StatusUpdaterTestSuite) TestStatusUpdater_matches() {
-	tests := []struct {
-		name      string
-		labels    map[string]string
-		selectors []coralv1beta1.NodeSelector
-		expected  bool
-	}{
-		{
-			name:      "empty selectors always match",
-			labels:    map[string]string{"key": "value"},
-			selectors: []coralv1beta1.NodeSelector{},
-			expected:  true,
-		},
 		{
-			name:   "single selector matches",
-			labels: map[string]string{"env": "prod"},
-			selectors: []coralv1beta1.NodeSelector{
-				{
-					Key:      "env",
-					Operator: selection.In,
-					Values:   []string{"prod"},
-				},
-			},
-			expected: true,
-		},
-		{
-			name:   "single selector does not match",
-			labels: map[string]string{"env": "dev"},
-			selectors: []coralv1beta1.NodeSelector{
-				{
-					Key:      "env",
-					Operator: selection.In,
-					Values:   []string{"prod"},
-				},
-			},
-			expected: false,
-		},
-		{
-			name:   "NotIn operator works",
-			labels: map[string]string{"env": "prod"},
-			selectors: []coralv1beta1.NodeSelector{
-				{
-					Key:      "env",
-					Operator: selection.NotIn,
-					Values:   []string{"dev", "staging"},
-				},
+			name: "invalid operator returns an error",
+			nodes: []corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{
+					Name:   "node1",
+					Labels: map[string]string{"env": "prod"},
+				}},
 			},
-			expected: true,
-		},
-		{
-			name:   "invalid operator returns false",
-			labels: map[string]string{"env": "prod"},
 			selectors: []coralv1beta1.NodeSelector{
 				{
 					Key:      "env",
@@ -200,15 +147,23 @@ func (s *StatusUpdaterTestSuite) TestStatusUpdater_matches() {
 					Values:   []string{"prod"},
 				},
 			},
-			expected: false,
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
 			updater := &StatusUpdater{}
-			result := updater.matches(tt.labels, tt.selectors)
-			s.Equal(tt.expected, result)
+			isync := &coralv1beta1.ImageSync{
+				Spec: coralv1beta1.ImageSyncSpec{NodeSelector: tt.selectors},
+			}
+			filtered, err := updater.filterNodes(tt.nodes, isync)
+			if tt.wantErr {
+				s.Error(err)
+				return
+			}
+			s.NoError(err)
+			s.Equal(tt.expected, len(filtered))
 		})
 	}
 }
@@ -329,6 +284,25 @@ func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_WithImageSync() {
 	// Overall condition should be based on the minimum (redis is the limiting factor)
 	s.Equal(1, updatedImageSync.Status.Condition.Available) // Min of nginx(2) and redis(1) = 1
 	s.Equal(1, updatedImageSync.Status.Condition.Pending)   // 2 total nodes - 1 available = 1 pending
+
+	s.True(meta.IsStatusConditionFalse(updatedImageSync.Status.Conditions, coralv1beta1.ConditionReady))
+	s.True(meta.IsStatusConditionTrue(updatedImageSync.Status.Conditions, coralv1beta1.ConditionProgressing))
+	s.True(meta.IsStatusConditionFalse(updatedImageSync.Status.Conditions, coralv1beta1.ConditionDegraded))
+
+	// Once all of the images are available the imagesync is ready.
+	s.nodeRef.AddImages("node2", []string{"docker.io/library/nginx:latest", "docker.io/library/redis:latest"})
+	s.NoError(updater.update(ctx))
+
+	err = s.client.Get(ctx, types.NamespacedName{Name: "test-imagesync-multi-image", Namespace: "default"}, &updatedImageSync)
+	s.NoError(err)
+
+	ready := meta.FindStatusCondition(updatedImageSync.Status.Conditions, coralv1beta1.ConditionReady)
+	s.NotNil(ready)
+	s.Equal(metav1.ConditionTrue, ready.Status)
+	s.Equal(coralv1beta1.ReasonAvailable, ready.Reason)
+	s.Equal(updatedImageSync.GetGeneration(), ready.ObservedGeneration)
+	s.Equal(updatedImageSync.GetGeneration(), updatedImageSync.Status.ObservedGeneration)
+	s.True(meta.IsStatusConditionFalse(updatedImageSync.Status.Conditions, coralv1beta1.ConditionProgressing))
 }
 
 func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_NoMatchingNodes() {
@@ -353,3 +327,152 @@ func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_NoMatchingNodes() {
 	s.Equal(0, updatedImageSync.Status.Condition.Available)
 	s.Equal(2, updatedImageSync.Status.Condition.Pending)
 }
+
+func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_InvalidNodeSelector() {
+	ctx := context.Background()
+	isync := &coralv1beta1.ImageSync{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-imagesync-invalid-selector", Namespace: "default"},
+		Spec: coralv1beta1.ImageSyncSpec{
+			Images: []string{"nginx:latest"},
+			NodeSelector: []coralv1beta1.NodeSelector{
+				{Key: "env", Operator: selection.Operator("InvalidOp"), Values: []string{"prod"}},
+			},
+		},
+	}
+	s.NoError(s.client.Create(ctx, isync))
+
+	updater := &StatusUpdater{
+		Client:  s.client,
+		nodeRef: s.nodeRef,
+	}
+
+	err := updater.update(ctx)
+	s.NoError(err)
+
+	var updatedImageSync coralv1beta1.ImageSync
+	err = s.client.Get(ctx, types.NamespacedName{Name: "test-imagesync-invalid-selector", Namespace: "default"}, &updatedImageSync)
+	s.NoError(err)
+
+	s.Equal(0, updatedImageSync.Status.TotalNodes)
+
+	conditions := updatedImageSync.Status.Conditions
+	ready := meta.FindStatusCondition(conditions, coralv1beta1.ConditionReady)
+	s.Require().NotNil(ready)
+	s.Equal(metav1.ConditionFalse, ready.Status)
+	s.Equal(coralv1beta1.ReasonInvalidNodeSelector, ready.Reason)
+	s.True(meta.IsStatusConditionFalse(conditions, coralv1beta1.ConditionProgressing))
+
+	degraded := meta.FindStatusCondition(conditions, coralv1beta1.ConditionDegraded)
+	s.Require().NotNil(degraded)
+	s.Equal(metav1.ConditionTrue, degraded.Status)
+	s.Equal(coralv1beta1.ReasonInvalidNodeSelector, degraded.Reason)
+	s.Contains(degraded.Message, "invalid node selector")
+}
+
+func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_Pinned() {
+	s.nodeRef.AddImages("node1", []string{"docker.io/library/nginx:latest"})
+	s.nodeRef.AddImages("node2", []string{"docker.io/library/nginx:latest"})
+	s.nodeRef.SetPinned("node1", []string{"docker.io/library/nginx:latest"})
+
+	updater := &StatusUpdater{
+		Client:  s.client,
+		nodeRef: s.nodeRef,
+	}
+
+	ctx := context.Background()
+	err := updater.update(ctx)
+	s.NoError(err)
+
+	var updatedImageSync coralv1beta1.ImageSync
+	err = s.client.Get(ctx, types.NamespacedName{Name: "test-imagesync-pinned", Namespace: "default"}, &updatedImageSync)
+	s.NoError(err)
+
+	s.Equal(2, updatedImageSync.Status.Condition.Available)
+	s.Equal(1, updatedImageSync.Status.Condition.Pinned)
+	s.Equal(1, updatedImageSync.Status.Images[0].Pinned)
+
+	// Pinned images are not counted for imagesyncs that have not enabled pinning.
+	err = s.client.Get(ctx, types.NamespacedName{Name: "test-imagesync-no-matching-nodes", Namespace: "default"}, &updatedImageSync)
+	s.NoError(err)
+	s.Equal(0, updatedImageSync.Status.Condition.Pinned)
+}
+
+func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_Failed() {
+	s.nodeRef.AddImages("node1", []string{})
+	s.nodeRef.SetFailed("node1", map[string]string{"docker.io/library/nginx:latest": "unauthorized"})
+	s.nodeRef.AddImages("node2", []string{})
+
+	updater := &StatusUpdater{
+		Client:  s.client,
+		nodeRef: s.nodeRef,
+	}
+
+	ctx := context.Background()
+	err := updater.update(ctx)
+	s.NoError(err)
+
+	var updatedImageSync coralv1beta1.ImageSync
+	err = s.client.Get(ctx, types.NamespacedName{Name: "test-imagesync-pinned", Namespace: "default"}, &updatedImageSync)
+	s.NoError(err)
+
+	s.Equal(0, updatedImageSync.Status.Condition.Available)
+	s.Equal(1, updatedImageSync.Status.Condition.Pending)
+	s.Equal(1, updatedImageSync.Status.Condition.Failed)
+	s.Equal(1, updatedImageSync.Status.Images[0].Pending)
+	s.Equal(1, updatedImageSync.Status.Images[0].Failed)
+	s.Equal([]coralv1beta1.ImageSyncFailure{
+		{Reason: "unauthorized", Nodes: 1},
+	}, updatedImageSync.Status.Images[0].Failures)
+
+	degraded := meta.FindStatusCondition(updatedImageSync.Status.Conditions, coralv1beta1.ConditionDegraded)
+	s.NotNil(degraded)
+	s.Equal(metav1.ConditionTrue, degraded.Status)
+	s.Equal(coralv1beta1.ReasonPullFailed, degraded.Reason)
+	s.Contains(degraded.Message, "unauthorized")
+	s.True(meta.IsStatusConditionTrue(updatedImageSync.Status.Conditions, coralv1beta1.ConditionProgressing))
+}
+
+func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_Cluster() {
+	s.client.ApplyFixtureOrDie("clusterimagesync.yaml")
+	s.nodeRef.AddImages("node1", []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+
+	updater := &StatusUpdater{
+		Client:  s.client,
+		nodeRef: s.nodeRef,
+	}
+
+	ctx := context.Background()
+	err := updater.update(ctx)
+	s.NoError(err)
+
+	var updated coralv1beta1.ClusterImageSync
+	err = s.client.Get(ctx, types.NamespacedName{Name: "example-cluster"}, &updated)
+	s.NoError(err)
+
+	s.Equal(2, updated.Status.TotalImages)
+	s.Equal(1, updated.Status.Condition.Available)
+	s.Len(updated.Status.Images, 2)
+}
+
+func (s *StatusUpdaterTestSuite) TestStatusUpdater_update_Digest() {
+	// node1 has the current digest while node2 still has the old image for the tag.
+	s.nodeRef.AddImages("node1", []string{"docker.io/library/nginx:latest", "docker.io/library/nginx@sha256:new"})
+	s.nodeRef.AddImages("node2", []string{"docker.io/library/nginx:latest", "docker.io/library/nginx@sha256:old"})
+
+	digests := store.NewDigests()
+	digests.Set("docker.io/library/nginx:latest", "sha256:new")
+
+	updater := NewStatusUpdater(s.client, s.nodeRef).WithDigests(digests)
+
+	ctx := context.Background()
+	err := updater.update(ctx)
+	s.NoError(err)
+
+	var updatedImageSync coralv1beta1.ImageSync
+	err = s.client.Get(ctx, types.NamespacedName{Name: "test-imagesync-pinned", Namespace: "default"}, &updatedImageSync)
+	s.NoError(err)
+
+	s.Equal("sha256:new", updatedImageSync.Status.Images[0].Digest)
+	s.Equal(1, updatedImageSync.Status.Images[0].Available)
+	s.Equal(1, updatedImageSync.Status.Images[0].Pending)
+}
@@ -16,27 +16,54 @@ package mirror
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const (
+	// RetryInterval is the delay before images that failed to mirror are first retried.
+	RetryInterval = 10 * time.Second
+	// MaxRetryInterval is the maximum delay before images that failed to mirror are retried.
+	MaxRetryInterval = 5 * time.Minute
+)
+
+// Pruner deletes mirrored images from the coral registry and reclaims the space used by
+// their blobs.
+type Pruner interface {
+	Remove(ctx context.Context, image string) error
+	Collect(ctx context.Context) error
+}
+
 type Options struct {
 	Registry string
+	Pruner   Pruner
 }
 
 type Controller struct {
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	Registry string
+	Pruner   Pruner
 	crclient.Client
 }
 
@@ -46,20 +73,95 @@ func SetupWithManager(mgr ctrl.Manager, opts *Options) error {
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("mirror-controller"),
 		Registry: opts.Registry,
+		Pruner:   opts.Pruner,
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&coralv1beta1.Mirror{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		For(&coralv1beta1.Mirror{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&coralv1beta1.ImagePolicy{}, handler.EnqueueRequestsFromMapFunc(c.policyMirrors),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&coralv1beta1.ClusterImagePolicy{}, handler.EnqueueRequestsFromMapFunc(c.policyMirrors),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// ConfigMaps and Secrets do not have a generation, so the registry configuration is
+		// watched without the generation predicate.
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(c.registryMirrors)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(c.registryMirrors)).
 		Complete(c)
 }
 
+// policyMirrors returns the mirrors that an image policy applies to.  Cluster image policies
+// apply to all of the mirrors.
+func (c *Controller) policyMirrors(ctx context.Context, obj crclient.Object) []reconcile.Request {
+	var list coralv1beta1.MirrorList
+	if err := c.List(ctx, &list, crclient.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list the mirrors for the image policy", "policy", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, mirror := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: crclient.ObjectKeyFromObject(&mirror),
+		})
+	}
+
+	return requests
+}
+
+// registryMirrors returns the mirrors whose registry configuration references the ConfigMap
+// or Secret, so that rotated CA bundles and client certificates are picked up.
+func (c *Controller) registryMirrors(ctx context.Context, obj crclient.Object) []reconcile.Request {
+	var list coralv1beta1.MirrorList
+	if err := c.List(ctx, &list, crclient.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list the mirrors for the registry configuration", "name", obj.GetName())
+		return nil
+	}
+
+	_, secret := obj.(*corev1.Secret)
+
+	requests := make([]reconcile.Request, 0)
+	for _, mirror := range list.Items {
+		if referencesRegistryObject(&mirror, obj.GetName(), secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: crclient.ObjectKeyFromObject(&mirror),
+			})
+		}
+	}
+
+	return requests
+}
+
+// referencesRegistryObject returns true if a registry of the mirror references the named
+// Secret, or ConfigMap if secret is false, for its CA bundle or client certificate.
+func referencesRegistryObject(mirror *coralv1beta1.Mirror, name string, secret bool) bool {
+	for _, registry := range mirror.Spec.Registries {
+		if ca := registry.CA; ca != nil {
+			if !secret && ca.ConfigMapKeyRef != nil && ca.ConfigMapKeyRef.Name == name {
+				return true
+			}
+			if secret && ca.SecretKeyRef != nil && ca.SecretKeyRef.Name == name {
+				return true
+			}
+		}
+
+		if secret && registry.ClientCertificate != nil && registry.ClientCertificate.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 // +kubebuilder:rbac:groups=coral.ctx.sh,resources=mirrors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coral.ctx.sh,resources=mirrors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=coral.ctx.sh,resources=imagepolicies;clusterimagepolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets;configmaps,verbs=get;list;watch
 
 func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Loop through the images in the Mirror spec and ensure that they are mirrored to coral.
-	// TODO(rob): Decide how to handle any removals.
+	// Images that have been removed from the spec are deleted from coral when the deletion
+	// policy allows it.
 	logger := ctrl.LoggerFrom(ctx)
 	logger.V(4).Info("reconciling mirror", "request", req)
 
@@ -96,26 +198,250 @@ func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, c.removeFinalizer(ctx, mirror)
 	}
 
+	// Images that are already mirrored are only copied again when they have changed, unless
+	// the mirror itself has changed.
+	resync := mirror.Status.ObservedGeneration == mirror.GetGeneration()
+
+	// Copying the images can take some time, so mark the mirror as progressing as soon as a
+	// new generation has been observed.
+	if !resync {
+		message := fmt.Sprintf("mirroring %d images", len(mirror.Spec.Images))
+		setCondition(mirror, coralv1beta1.ConditionReady, metav1.ConditionFalse, coralv1beta1.ReasonMirroring, message)
+		setCondition(mirror, coralv1beta1.ConditionProgressing, metav1.ConditionTrue, coralv1beta1.ReasonMirroring, message)
+		mirror.Status.ObservedGeneration = mirror.GetGeneration()
+		mirror.Status.TotalImages = len(mirror.Spec.Images)
+		if err := c.Status().Update(ctx, mirror); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	policy, err := NewPolicy(observed.ClusterImagePolicies, observed.ImagePolicies)
+	if err != nil {
+		logger.Error(err, "invalid image policy")
+		message := "invalid image policy: " + err.Error()
+		setCondition(mirror, coralv1beta1.ConditionReady, metav1.ConditionFalse, coralv1beta1.ReasonInvalidImagePolicy, message)
+		setCondition(mirror, coralv1beta1.ConditionProgressing, metav1.ConditionFalse, coralv1beta1.ReasonInvalidImagePolicy, message)
+		setCondition(mirror, coralv1beta1.ConditionDegraded, metav1.ConditionTrue, coralv1beta1.ReasonInvalidImagePolicy, message)
+		c.Recorder.Event(mirror, corev1.EventTypeWarning, coralv1beta1.ReasonInvalidImagePolicy, message)
+		// The mirror is reconciled again when the image policies change.
+		return ctrl.Result{}, c.Status().Update(ctx, mirror)
+	}
+
+	if observed.TransportError != nil {
+		logger.Error(observed.TransportError, "invalid registry configuration")
+		message := "invalid registry configuration: " + observed.TransportError.Error()
+		setCondition(mirror, coralv1beta1.ConditionReady, metav1.ConditionFalse, coralv1beta1.ReasonInvalidRegistryConfig, message)
+		setCondition(mirror, coralv1beta1.ConditionProgressing, metav1.ConditionFalse, coralv1beta1.ReasonInvalidRegistryConfig, message)
+		setCondition(mirror, coralv1beta1.ConditionDegraded, metav1.ConditionTrue, coralv1beta1.ReasonInvalidRegistryConfig, message)
+		c.Recorder.Event(mirror, corev1.EventTypeWarning, coralv1beta1.ReasonInvalidRegistryConfig, message)
+		// The mirror is reconciled again when the referenced ConfigMaps and Secrets change.
+		return ctrl.Result{}, c.Status().Update(ctx, mirror)
+	}
+
 	syncer := NewSynchronizer().
 		WithDestinationRegistry(c.Registry).
 		WithCopyAll(*observed.Mirror.Spec.CopyAllArchitectures).
-		WithImagePullSecrets(observed.Secrets)
+		WithImagePullSecrets(observed.Secrets).
+		WithTransports(observed.Transports).
+		WithPolicy(policy)
 
-	syncError := false
+	now := metav1.Now()
+	failures := 0
+	failed := make([]string, 0)
+	images := make([]coralv1beta1.MirrorImage, 0, len(observed.Mirror.Spec.Images))
 	for _, image := range observed.Mirror.Spec.Images {
-		if err := syncer.Copy(ctx, image); err != nil {
+		status, ok := imageStatus(mirror, syncer, image)
+
+		source, current := upToDate(ctx, syncer, image, status)
+		if resync && current {
+			logger.V(6).Info("mirrored image is up to date", "image", image, "digest", source)
+			images = append(images, status)
+			continue
+		}
+
+		copied, err := syncer.Copy(ctx, image)
+		if err != nil {
 			logger.Error(err, "failed to sync image", "image", image)
-			syncError = true
+			failed = append(failed, image)
+			status.LastError = err.Error()
+			status.ConsecutiveFailures++
+			status.Rejection = rejection(err)
+			if status.Rejection != "" {
+				c.Recorder.Eventf(mirror, corev1.EventTypeWarning, coralv1beta1.ReasonPolicyRejected,
+					"image policy rejected %s: %s", image, status.Rejection)
+			} else {
+				c.Recorder.Eventf(mirror, corev1.EventTypeWarning, coralv1beta1.ReasonMirrorFailed,
+					"failed to mirror %s: %s", image, err.Error())
+			}
+		} else {
+			status.Digest = copied.Digest
+			status.SourceDigest = source
+			status.Platforms = copied.Platforms
+			status.LastSyncTime = &now
+			status.LastError = ""
+			status.ConsecutiveFailures = 0
+			status.Rejection = ""
+			c.Recorder.Eventf(mirror, corev1.EventTypeNormal, coralv1beta1.ReasonMirrored,
+				"mirrored %s to %s at %s", image, status.Destination, copied.Digest)
+		}
+
+		if ok {
+			images = append(images, status)
+			failures = max(failures, status.ConsecutiveFailures)
+		}
+	}
+
+	// Images that could not be deleted are kept in the status so they are retried.
+	pruned := true
+	if mirror.Spec.DeletionPolicy == coralv1beta1.MirrorDeletionPolicyDelete {
+		retained, err := c.prune(ctx, mirror, syncer, removedImages(observed.Mirror.Status.Images, images))
+		if err != nil {
+			logger.Error(err, "failed to prune images")
+			pruned = false
 		}
+		images = append(images, retained...)
+	}
+	mirror.Status.Images = images
+
+	if err := c.updateStatus(ctx, mirror, &observed.Mirror.Status, failed); err != nil {
+		logger.Error(err, "failed to update mirror status")
+		return ctrl.Result{}, err
+	}
+
+	if len(failed) > 0 || !pruned {
+		return ctrl.Result{RequeueAfter: backoff(failures)}, nil
 	}
 
-	if syncError {
-		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	if interval := mirror.Spec.SyncInterval; interval != nil && interval.Duration > 0 {
+		return ctrl.Result{RequeueAfter: interval.Duration}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// digester returns the manifest digests of the images in the source and destination
+// registries.
+type digester interface {
+	SourceDigest(ctx context.Context, image string) (string, error)
+	DestinationDigest(ctx context.Context, image string) (string, error)
+}
+
+// upToDate returns the digest of the source manifest and whether the copy of the image is up
+// to date.  The copy is up to date when the source manifest has not changed since the last
+// copy and the destination still holds the copied manifest.  Only the manifests are requested
+// so the check is cheap compared to a copy.
+func upToDate(ctx context.Context, d digester, image string, status coralv1beta1.MirrorImage) (string, bool) {
+	source, err := d.SourceDigest(ctx, image)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).V(4).Info("unable to get the source digest", "image", image, "error", err.Error())
+		return "", false
+	}
+
+	if status.LastSyncTime == nil || status.SourceDigest != source {
+		return source, false
+	}
+
+	destination, err := d.DestinationDigest(ctx, image)
+	if err != nil || destination != status.Digest {
+		return source, false
+	}
+
+	return source, true
+}
+
+// updateStatus sets the conditions on the mirror based on the images that failed to mirror.
+// The status is only written when it differs from the previous status.
+func (c *Controller) updateStatus(ctx context.Context, mirror *coralv1beta1.Mirror, previous *coralv1beta1.MirrorStatus, failed []string) error {
+	total := len(mirror.Spec.Images)
+
+	if len(failed) == 0 {
+		message := fmt.Sprintf("all %d images have been mirrored", total)
+		setCondition(mirror, coralv1beta1.ConditionReady, metav1.ConditionTrue, coralv1beta1.ReasonMirrored, message)
+		setCondition(mirror, coralv1beta1.ConditionProgressing, metav1.ConditionFalse, coralv1beta1.ReasonMirrored, message)
+		setCondition(mirror, coralv1beta1.ConditionDegraded, metav1.ConditionFalse, coralv1beta1.ReasonAsExpected, "no images failed to mirror")
+	} else {
+		// Images that were refused by the image policy take precedence as they will keep
+		// failing until the policy or the signatures change.
+		reason := coralv1beta1.ReasonMirrorFailed
+		degraded := "failed to mirror: " + strings.Join(failed, ", ")
+		if rejected := rejectedImages(mirror); len(rejected) > 0 {
+			reason = coralv1beta1.ReasonPolicyRejected
+			degraded = "rejected by the image policy: " + strings.Join(rejected, ", ")
+		}
+
+		message := fmt.Sprintf("%d of %d images failed to mirror", len(failed), total)
+		setCondition(mirror, coralv1beta1.ConditionReady, metav1.ConditionFalse, reason, message)
+		setCondition(mirror, coralv1beta1.ConditionProgressing, metav1.ConditionTrue, reason, "retrying the images that failed to mirror")
+		setCondition(mirror, coralv1beta1.ConditionDegraded, metav1.ConditionTrue, reason, degraded)
+	}
+
+	mirror.Status.ObservedGeneration = mirror.GetGeneration()
+	mirror.Status.TotalImages = total
+
+	// Resyncs that did not change anything do not need to write the status.
+	if previous != nil && equality.Semantic.DeepEqual(*previous, mirror.Status) {
+		return nil
+	}
+
+	return c.Status().Update(ctx, mirror)
+}
+
+// rejectedImages returns the images that were refused by the image policy.
+func rejectedImages(mirror *coralv1beta1.Mirror) []string {
+	rejected := make([]string, 0)
+	for _, image := range mirror.Status.Images {
+		if image.Rejection != "" {
+			rejected = append(rejected, image.Image)
+		}
+	}
+
+	return rejected
+}
+
+// backoff returns the delay before the failed images are retried.  The delay doubles with
+// each consecutive failure up to MaxRetryInterval.
+func backoff(failures int) time.Duration {
+	delay := RetryInterval
+	for i := 1; i < failures && delay < MaxRetryInterval; i++ {
+		delay *= 2
+	}
+
+	return min(delay, MaxRetryInterval)
+}
+
+// imageStatus returns the current status of the image, carrying over the previous status of
+// the same image.  False is returned if the image is not a valid reference.
+func imageStatus(mirror *coralv1beta1.Mirror, syncer *Synchronizer, image string) (coralv1beta1.MirrorImage, bool) {
+	ref, err := util.ParseReference(image)
+	if err != nil {
+		return coralv1beta1.MirrorImage{}, false
+	}
+
+	dst, _ := syncer.Destination(image)
+	status := coralv1beta1.MirrorImage{
+		Image:       ref.String(),
+		Destination: dst,
+	}
+
+	for _, previous := range mirror.Status.Images {
+		if previous.Image == status.Image && previous.Destination == status.Destination {
+			status = *previous.DeepCopy()
+		}
+	}
+
+	return status, true
+}
+
+func setCondition(mirror *coralv1beta1.Mirror, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mirror.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: mirror.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
 func (c *Controller) addFinalizer(ctx context.Context, mirror *coralv1beta1.Mirror) error {
 	controllerutil.AddFinalizer(mirror, coralv1beta1.MirrorFinalizer)
 	if err := c.Update(ctx, mirror); err != nil {
@@ -140,8 +466,116 @@ func (c *Controller) removeFinalizer(ctx context.Context, mirror *coralv1beta1.M
 	return nil
 }
 
+// finalize deletes the mirrored images from the registry when the deletion policy is Delete.
 func (c *Controller) finalize(ctx context.Context, mirror *coralv1beta1.Mirror) error {
-	// TODO: I'm not currently using the finalizers.  This is in place in case we want to
-	//   clean up the registry after the deletion.
-	return nil
+	if mirror.Spec.DeletionPolicy != coralv1beta1.MirrorDeletionPolicyDelete {
+		return nil
+	}
+
+	syncer := NewSynchronizer().WithDestinationRegistry(c.Registry)
+	_, err := c.prune(ctx, mirror, syncer, mirror.Status.Images)
+
+	return err
+}
+
+// prune deletes the images from the registry and returns the images that could not be
+// deleted.  Images that are still mirrored by another mirror are left in place.  The blobs
+// are garbage collected once any image has been deleted, which is safe as mirrors are
+// reconciled one at a time.
+func (c *Controller) prune(ctx context.Context, mirror *coralv1beta1.Mirror, syncer *Synchronizer, images []coralv1beta1.MirrorImage) ([]coralv1beta1.MirrorImage, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	if len(images) == 0 {
+		return nil, nil
+	}
+
+	if c.Pruner == nil {
+		logger.V(4).Info("the registry does not support pruning, retaining images")
+		return nil, nil
+	}
+
+	var list coralv1beta1.MirrorList
+	if err := c.List(ctx, &list); err != nil {
+		return images, err
+	}
+
+	referenced := referencedDestinations(list.Items, mirror, syncer)
+
+	deleted := 0
+	retained := make([]coralv1beta1.MirrorImage, 0)
+	errs := make([]error, 0)
+	for _, image := range images {
+		if referenced[image.Destination] {
+			logger.V(4).Info("image is still mirrored by another mirror, retaining", "image", image.Destination)
+			continue
+		}
+
+		if err := c.Pruner.Remove(ctx, image.Destination); err != nil {
+			retained = append(retained, image)
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", image.Destination, err))
+			c.Recorder.Eventf(mirror, corev1.EventTypeWarning, coralv1beta1.ReasonPruneFailed,
+				"failed to delete %s: %s", image.Destination, err.Error())
+			continue
+		}
+
+		deleted++
+		c.Recorder.Eventf(mirror, corev1.EventTypeNormal, coralv1beta1.ReasonPruned,
+			"deleted %s from the registry", image.Destination)
+	}
+
+	// A failed collection is picked up by the next collection, so it does not need to be
+	// retried.
+	if deleted > 0 {
+		if err := c.Pruner.Collect(ctx); err != nil {
+			logger.Error(err, "failed to garbage collect the registry")
+		}
+	}
+
+	return retained, errors.Join(errs...)
+}
+
+// referencedDestinations returns the destinations of the images mirrored by the other
+// mirrors.  Mirrors that are being deleted with the Delete policy no longer hold on to
+// their images.
+func referencedDestinations(mirrors []coralv1beta1.Mirror, mirror *coralv1beta1.Mirror, syncer *Synchronizer) map[string]bool {
+	referenced := make(map[string]bool)
+	for _, other := range mirrors {
+		if other.GetNamespace() == mirror.GetNamespace() && other.GetName() == mirror.GetName() {
+			continue
+		}
+
+		if !other.DeletionTimestamp.IsZero() && other.Spec.DeletionPolicy == coralv1beta1.MirrorDeletionPolicyDelete {
+			continue
+		}
+
+		for _, image := range other.Spec.Images {
+			if dst, err := syncer.Destination(image); err == nil {
+				referenced[dst] = true
+			}
+		}
+
+		for _, image := range other.Status.Images {
+			referenced[image.Destination] = true
+		}
+	}
+
+	return referenced
+}
+
+// removedImages returns the previously mirrored images whose destination is no longer
+// mirrored.
+func removedImages(previous, current []coralv1beta1.MirrorImage) []coralv1beta1.MirrorImage {
+	mirrored := make(map[string]bool, len(current))
+	for _, image := range current {
+		mirrored[image.Destination] = true
+	}
+
+	removed := make([]coralv1beta1.MirrorImage, 0)
+	for _, image := range previous {
+		if !mirrored[image.Destination] {
+			removed = append(removed, image)
+		}
+	}
+
+	return removed
 }
@@ -16,6 +16,7 @@ package mirror
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
@@ -24,12 +25,17 @@ import (
 	"ctx.sh/coral/pkg/mock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 type ControllerTestSuite struct {
@@ -181,15 +187,128 @@ func (s *ControllerTestSuite) TestController_finalize() {
 
 	err = controller.finalize(ctx, &mirror)
 
-	// Currently finalize is a no-op, so should not return error
+	// The images are retained by default, so there is nothing to delete.
 	s.NoError(err)
 }
 
+type fakePruner struct {
+	removed   []string
+	failed    map[string]bool
+	collected int
+}
+
+func (f *fakePruner) Remove(_ context.Context, image string) error {
+	if f.failed[image] {
+		return errors.New("unavailable")
+	}
+
+	f.removed = append(f.removed, image)
+	return nil
+}
+
+func (f *fakePruner) Collect(_ context.Context) error {
+	f.collected++
+	return nil
+}
+
+func (s *ControllerTestSuite) TestController_finalize_delete() {
+	pruner := &fakePruner{}
+	controller := &Controller{
+		Client:   s.client,
+		Registry: "localhost:5000",
+		Recorder: record.NewFakeRecorder(10),
+		Pruner:   pruner,
+	}
+
+	ctx := context.Background()
+	var mirror coralctxshv1beta1.Mirror
+	err := s.client.Get(ctx, types.NamespacedName{Name: "test-mirror-delete", Namespace: "default"}, &mirror)
+	s.NoError(err)
+
+	err = controller.finalize(ctx, &mirror)
+	s.NoError(err)
+
+	// Redis and nginx are still mirrored by other mirrors.
+	s.Equal([]string{
+		"localhost:5000/library/busybox:latest",
+		"localhost:5000/library/alpine:3",
+	}, pruner.removed)
+	s.Equal(1, pruner.collected)
+}
+
+func (s *ControllerTestSuite) TestController_prune() {
+	ctx := context.Background()
+	var mirror coralctxshv1beta1.Mirror
+	err := s.client.Get(ctx, types.NamespacedName{Name: "test-mirror-delete", Namespace: "default"}, &mirror)
+	s.NoError(err)
+
+	syncer := NewSynchronizer().WithDestinationRegistry("localhost:5000")
+	removed := removedImages(mirror.Status.Images, mirror.Status.Images[:1])
+
+	s.Run("removed images are deleted", func() {
+		pruner := &fakePruner{}
+		controller := &Controller{
+			Client:   s.client,
+			Recorder: record.NewFakeRecorder(10),
+			Pruner:   pruner,
+		}
+
+		retained, err := controller.prune(ctx, &mirror, syncer, removed)
+		s.NoError(err)
+		s.Empty(retained)
+		s.Equal([]string{"localhost:5000/library/alpine:3"}, pruner.removed)
+		s.Equal(1, pruner.collected)
+	})
+
+	s.Run("images that fail to delete are retained", func() {
+		pruner := &fakePruner{failed: map[string]bool{"localhost:5000/library/alpine:3": true}}
+		controller := &Controller{
+			Client:   s.client,
+			Recorder: record.NewFakeRecorder(10),
+			Pruner:   pruner,
+		}
+
+		retained, err := controller.prune(ctx, &mirror, syncer, removed)
+		s.Error(err)
+		s.Len(retained, 1)
+		s.Equal("localhost:5000/library/alpine:3", retained[0].Destination)
+		s.Equal(0, pruner.collected)
+	})
+
+	s.Run("images are retained without a pruner", func() {
+		controller := &Controller{
+			Client: s.client,
+		}
+
+		retained, err := controller.prune(ctx, &mirror, syncer, removed)
+		s.NoError(err)
+		s.Empty(retained)
+	})
+}
+
+func (s *ControllerTestSuite) TestRemovedImages() {
+	previous := []coralctxshv1beta1.MirrorImage{
+		{Image: "docker.io/library/nginx:latest", Destination: "localhost:5000/library/nginx:latest"},
+		{Image: "docker.io/library/redis:7", Destination: "localhost:5000/library/redis:7"},
+	}
+	current := []coralctxshv1beta1.MirrorImage{
+		{Image: "docker.io/library/nginx:latest", Destination: "localhost:5000/library/nginx:latest"},
+		{Image: "docker.io/library/alpine:3", Destination: "localhost:5000/library/alpine:3"},
+	}
+
+	removed := removedImages(previous, current)
+	s.Len(removed, 1)
+	s.Equal("localhost:5000/library/redis:7", removed[0].Destination)
+
+	s.Empty(removedImages(nil, current))
+}
+
 func (s *ControllerTestSuite) TestController_Reconcile_SuccessfulFlow() {
+	recorder := record.NewFakeRecorder(10)
 	controller := &Controller{
 		Client:   s.client,
 		Registry: "localhost:5000",
-		Recorder: &record.FakeRecorder{},
+		Recorder: recorder,
 	}
 
 	ctx := context.Background()
@@ -206,6 +325,181 @@ func (s *ControllerTestSuite) TestController_Reconcile_SuccessfulFlow() {
 	// since we can't mock the containers/image operations easily, so it will requeue
 	s.NoError(err)
 	s.Equal(time.Second*10, result.RequeueAfter)
+
+	var mirror coralctxshv1beta1.Mirror
+	err = s.client.Get(ctx, req.NamespacedName, &mirror)
+	s.NoError(err)
+
+	s.Equal(2, mirror.Status.TotalImages)
+	s.Equal(mirror.GetGeneration(), mirror.Status.ObservedGeneration)
+	s.True(meta.IsStatusConditionFalse(mirror.Status.Conditions, coralctxshv1beta1.ConditionReady))
+	s.True(meta.IsStatusConditionTrue(mirror.Status.Conditions, coralctxshv1beta1.ConditionProgressing))
+
+	degraded := meta.FindStatusCondition(mirror.Status.Conditions, coralctxshv1beta1.ConditionDegraded)
+	s.NotNil(degraded)
+	s.Equal(metav1.ConditionTrue, degraded.Status)
+	s.Equal(coralctxshv1beta1.ReasonMirrorFailed, degraded.Reason)
+	s.Contains(degraded.Message, "nginx:latest")
+
+	s.Require().Len(mirror.Status.Images, 2)
+	image := mirror.Status.Images[0]
+	s.Equal("docker.io/library/nginx:latest", image.Image)
+	s.Equal("localhost:5000/library/nginx:latest", image.Destination)
+	s.Equal(1, image.ConsecutiveFailures)
+	s.NotEmpty(image.LastError)
+	s.Nil(image.LastSyncTime)
+
+	s.Len(recorder.Events, 2)
+	s.Contains(<-recorder.Events, "Warning "+coralctxshv1beta1.ReasonMirrorFailed+" failed to mirror nginx:latest")
+
+	// The failures are counted across reconciles and the retry backs off.
+	result, err = controller.Reconcile(ctx, req)
+	s.NoError(err)
+	s.Equal(20*time.Second, result.RequeueAfter)
+
+	err = s.client.Get(ctx, req.NamespacedName, &mirror)
+	s.NoError(err)
+	s.Equal(2, mirror.Status.Images[0].ConsecutiveFailures)
+}
+
+func (s *ControllerTestSuite) TestController_Reconcile_InvalidRegistryConfig() {
+	recorder := record.NewFakeRecorder(10)
+	controller := &Controller{
+		Client:   s.client,
+		Registry: "localhost:5000",
+		Recorder: recorder,
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-mirror-registries-missing",
+			Namespace: "default",
+		},
+	}
+
+	result, err := controller.Reconcile(ctx, req)
+	s.NoError(err)
+	s.Equal(ctrl.Result{}, result)
+
+	var mirror coralctxshv1beta1.Mirror
+	s.Require().NoError(s.client.Get(ctx, req.NamespacedName, &mirror))
+
+	s.True(meta.IsStatusConditionFalse(mirror.Status.Conditions, coralctxshv1beta1.ConditionReady))
+	degraded := meta.FindStatusCondition(mirror.Status.Conditions, coralctxshv1beta1.ConditionDegraded)
+	s.Require().NotNil(degraded)
+	s.Equal(metav1.ConditionTrue, degraded.Status)
+	s.Equal(coralctxshv1beta1.ReasonInvalidRegistryConfig, degraded.Reason)
+	s.Contains(degraded.Message, "missing-ca")
+
+	s.Require().Len(recorder.Events, 1)
+	s.Contains(<-recorder.Events, "Warning "+coralctxshv1beta1.ReasonInvalidRegistryConfig)
+}
+
+func (s *ControllerTestSuite) TestController_registryMirrors() {
+	controller := &Controller{
+		Client: s.client,
+	}
+
+	ctx := context.Background()
+	key := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+	}
+
+	tests := []struct {
+		name     string
+		obj      crclient.Object
+		expected []reconcile.Request
+	}{
+		{
+			name:     "configmap holding a CA bundle",
+			obj:      &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "registry-ca"}},
+			expected: []reconcile.Request{key("test-mirror-registries")},
+		},
+		{
+			name:     "missing configmap holding a CA bundle",
+			obj:      &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "missing-ca"}},
+			expected: []reconcile.Request{key("test-mirror-registries-missing")},
+		},
+		{
+			name:     "secret holding a client certificate",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "registry-client"}},
+			expected: []reconcile.Request{key("test-mirror-registries")},
+		},
+		{
+			name:     "secret with the name of a configmap",
+			obj:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "registry-ca"}},
+			expected: []reconcile.Request{},
+		},
+		{
+			name:     "unreferenced configmap",
+			obj:      &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unrelated"}},
+			expected: []reconcile.Request{},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.expected, controller.registryMirrors(ctx, tt.obj))
+		})
+	}
+}
+
+func (s *ControllerTestSuite) TestBackoff() {
+	s.Equal(RetryInterval, backoff(0))
+	s.Equal(RetryInterval, backoff(1))
+	s.Equal(40*time.Second, backoff(3))
+	s.Equal(MaxRetryInterval, backoff(100))
+}
+
+func (s *ControllerTestSuite) TestController_updateStatus() {
+	controller := &Controller{
+		Client: s.client,
+	}
+
+	ctx := context.Background()
+	var mirror coralctxshv1beta1.Mirror
+	err := s.client.Get(ctx, types.NamespacedName{Name: "test-mirror", Namespace: "default"}, &mirror)
+	s.NoError(err)
+
+	err = controller.updateStatus(ctx, &mirror, nil, []string{})
+	s.NoError(err)
+
+	ready := meta.FindStatusCondition(mirror.Status.Conditions, coralctxshv1beta1.ConditionReady)
+	s.NotNil(ready)
+	s.Equal(metav1.ConditionTrue, ready.Status)
+	s.Equal(coralctxshv1beta1.ReasonMirrored, ready.Reason)
+	s.True(meta.IsStatusConditionFalse(mirror.Status.Conditions, coralctxshv1beta1.ConditionProgressing))
+	s.True(meta.IsStatusConditionFalse(mirror.Status.Conditions, coralctxshv1beta1.ConditionDegraded))
+}
+
+func (s *ControllerTestSuite) TestController_updateStatus_rejected() {
+	controller := &Controller{
+		Client: s.client,
+	}
+
+	ctx := context.Background()
+	var mirror coralctxshv1beta1.Mirror
+	err := s.client.Get(ctx, types.NamespacedName{Name: "test-mirror", Namespace: "default"}, &mirror)
+	s.NoError(err)
+
+	mirror.Status.Images = []coralctxshv1beta1.MirrorImage{
+		{
+			Image:       "docker.io/library/nginx:latest",
+			Destination: "localhost:5000/library/nginx:latest",
+			LastError:   "Source image rejected: A signature was required, but no signature exists",
+			Rejection:   "A signature was required, but no signature exists",
+		},
+	}
+
+	err = controller.updateStatus(ctx, &mirror, nil, []string{"nginx:latest"})
+	s.NoError(err)
+
+	degraded := meta.FindStatusCondition(mirror.Status.Conditions, coralctxshv1beta1.ConditionDegraded)
+	s.NotNil(degraded)
+	s.Equal(metav1.ConditionTrue, degraded.Status)
+	s.Equal(coralctxshv1beta1.ReasonPolicyRejected, degraded.Reason)
+	s.Equal("rejected by the image policy: docker.io/library/nginx:latest", degraded.Message)
 }
 
 func (s *ControllerTestSuite) TestController_Reconcile_WithDeletionTimestamp() {
@@ -247,3 +541,113 @@ func (s *ControllerTestSuite) TestController_Reconcile_NeedsFinalizer() {
 	s.NoError(err)
 	s.True(result.Requeue) // nolint:staticcheck
 }
+
+func (s *ControllerTestSuite) TestImageStatus() {
+	synced := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	mirror := &coralctxshv1beta1.Mirror{
+		Status: coralctxshv1beta1.MirrorStatus{
+			Images: []coralctxshv1beta1.MirrorImage{
+				{
+					Image:        "docker.io/library/nginx:latest",
+					Destination:  "localhost:5000/library/nginx:latest",
+					Digest:       "sha256:aaa",
+					LastSyncTime: &synced,
+				},
+			},
+		},
+	}
+
+	syncer := NewSynchronizer().WithDestinationRegistry("localhost:5000")
+
+	status, ok := imageStatus(mirror, syncer, "nginx")
+	s.True(ok)
+	s.Equal("docker.io/library/nginx:latest", status.Image)
+	s.Equal("localhost:5000/library/nginx:latest", status.Destination)
+	s.Equal("sha256:aaa", status.Digest)
+	s.Equal(&synced, status.LastSyncTime)
+
+	status, ok = imageStatus(mirror, syncer, "quay.io/prometheus/prometheus:v3.0.0")
+	s.True(ok)
+	s.Equal("localhost:5000/prometheus/prometheus:v3.0.0", status.Destination)
+	s.Nil(status.LastSyncTime)
+
+	_, ok = imageStatus(mirror, syncer, "INVALID")
+	s.False(ok)
+}
+
+type fakeDigester struct {
+	source      string
+	destination string
+	err         error
+}
+
+func (f *fakeDigester) SourceDigest(_ context.Context, _ string) (string, error) {
+	return f.source, f.err
+}
+
+func (f *fakeDigester) DestinationDigest(_ context.Context, _ string) (string, error) {
+	return f.destination, f.err
+}
+
+func (s *ControllerTestSuite) TestUpToDate() {
+	synced := metav1.Now()
+	status := coralctxshv1beta1.MirrorImage{
+		Image:        "docker.io/library/nginx:latest",
+		Destination:  "localhost:5000/library/nginx:latest",
+		Digest:       "sha256:copy",
+		SourceDigest: "sha256:source",
+		LastSyncTime: &synced,
+	}
+
+	tests := []struct {
+		name     string
+		digester *fakeDigester
+		status   coralctxshv1beta1.MirrorImage
+		source   string
+		current  bool
+	}{
+		{
+			name:     "unchanged source and destination",
+			digester: &fakeDigester{source: "sha256:source", destination: "sha256:copy"},
+			status:   status,
+			source:   "sha256:source",
+			current:  true,
+		},
+		{
+			name:     "the source tag has moved",
+			digester: &fakeDigester{source: "sha256:moved", destination: "sha256:copy"},
+			status:   status,
+			source:   "sha256:moved",
+			current:  false,
+		},
+		{
+			name:     "the copy is missing from the destination",
+			digester: &fakeDigester{source: "sha256:source", destination: "sha256:other"},
+			status:   status,
+			source:   "sha256:source",
+			current:  false,
+		},
+		{
+			name:     "the image has never been copied",
+			digester: &fakeDigester{source: "sha256:source", destination: "sha256:copy"},
+			status:   coralctxshv1beta1.MirrorImage{Image: status.Image, Destination: status.Destination},
+			source:   "sha256:source",
+			current:  false,
+		},
+		{
+			name:     "the source can not be reached",
+			digester: &fakeDigester{err: errors.New("unreachable")},
+			status:   status,
+			source:   "",
+			current:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			source, current := upToDate(context.Background(), tt.digester, "nginx", tt.status)
+			s.Equal(tt.source, source)
+			s.Equal(tt.current, current)
+		})
+	}
+}
@@ -16,6 +16,8 @@ package mirror
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"time"
 
 	coralctxshv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
@@ -26,16 +28,23 @@ import (
 )
 
 type ObservedState struct {
-	Mirror      *coralctxshv1beta1.Mirror
-	Secrets     []corev1.Secret
-	ObserveTime time.Time
+	Mirror               *coralctxshv1beta1.Mirror
+	Secrets              []corev1.Secret
+	ClusterImagePolicies []coralctxshv1beta1.ClusterImagePolicy
+	ImagePolicies        []coralctxshv1beta1.ImagePolicy
+	Transports           map[string]Transport
+	TransportError       error
+	ObserveTime          time.Time
 }
 
 func NewObservedState() *ObservedState {
 	return &ObservedState{
-		Mirror:      nil,
-		Secrets:     make([]corev1.Secret, 0),
-		ObserveTime: time.Now(),
+		Mirror:               nil,
+		Secrets:              make([]corev1.Secret, 0),
+		ClusterImagePolicies: make([]coralctxshv1beta1.ClusterImagePolicy, 0),
+		ImagePolicies:        make([]coralctxshv1beta1.ImagePolicy, 0),
+		Transports:           make(map[string]Transport),
+		ObserveTime:          time.Now(),
 	}
 }
 
@@ -64,6 +73,27 @@ func (o *StateObserver) observe(ctx context.Context, observed *ObservedState) er
 	}
 	observed.Secrets = observedSecrets
 
+	// A registry configuration that can not be resolved is reported in the mirror status
+	// rather than failing the observation.
+	observedTransports, err := o.getTransports(ctx, observedMirror.Spec.Registries)
+	if err != nil {
+		observed.TransportError = err
+	} else {
+		observed.Transports = observedTransports
+	}
+
+	var clusterPolicies coralctxshv1beta1.ClusterImagePolicyList
+	if err := o.Client.List(ctx, &clusterPolicies); err != nil {
+		return err
+	}
+	observed.ClusterImagePolicies = clusterPolicies.Items
+
+	var policies coralctxshv1beta1.ImagePolicyList
+	if err := o.Client.List(ctx, &policies, client.InNamespace(observedMirror.GetNamespace())); err != nil {
+		return err
+	}
+	observed.ImagePolicies = policies.Items
+
 	return nil
 }
 
@@ -95,6 +125,89 @@ func (o *StateObserver) getSecrets(ctx context.Context, imagePullSecrets []corev
 	return secrets, nil
 }
 
+// getTransports resolves the CA bundles and client certificates of the registries.  Unlike the
+// image pull secrets, missing references are errors as the registry could not be verified.
+func (o *StateObserver) getTransports(ctx context.Context, registries []coralctxshv1beta1.MirrorRegistry) (map[string]Transport, error) {
+	transports := make(map[string]Transport)
+	for _, registry := range registries {
+		transport := Transport{
+			Insecure: registry.Insecure,
+		}
+
+		if registry.Proxy != "" {
+			proxy, err := url.Parse(registry.Proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy for %s: %w", registry.Host, err)
+			}
+			transport.Proxy = proxy
+		}
+
+		if registry.CA != nil {
+			ca, err := o.getCABundle(ctx, registry.CA)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get the CA bundle for %s: %w", registry.Host, err)
+			}
+			transport.CA = ca
+		}
+
+		if registry.ClientCertificate != nil {
+			secret, err := o.getSecret(ctx, registry.ClientCertificate.Name)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get the client certificate for %s: %w", registry.Host, err)
+			}
+
+			transport.Certificate = secret.Data[corev1.TLSCertKey]
+			transport.Key = secret.Data[corev1.TLSPrivateKeyKey]
+			if len(transport.Certificate) == 0 || len(transport.Key) == 0 {
+				return nil, fmt.Errorf("client certificate secret %s for %s must contain %s and %s",
+					secret.Name, registry.Host, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+			}
+		}
+
+		transports[registry.Host] = transport
+	}
+
+	return transports, nil
+}
+
+// getCABundle returns the CA bundle from the ConfigMap or Secret key.
+func (o *StateObserver) getCABundle(ctx context.Context, source *coralctxshv1beta1.CABundleSource) ([]byte, error) {
+	switch {
+	case source.ConfigMapKeyRef != nil:
+		ref := source.ConfigMapKeyRef
+		configMap := corev1.ConfigMap{}
+		if err := o.Client.Get(ctx, types.NamespacedName{
+			Namespace: o.Request.Namespace,
+			Name:      ref.Name,
+		}, &configMap); err != nil {
+			return nil, err
+		}
+
+		if data, ok := configMap.Data[ref.Key]; ok {
+			return []byte(data), nil
+		}
+		if data, ok := configMap.BinaryData[ref.Key]; ok {
+			return data, nil
+		}
+
+		return nil, fmt.Errorf("key %s not found in configmap %s", ref.Key, ref.Name)
+	case source.SecretKeyRef != nil:
+		ref := source.SecretKeyRef
+		secret, err := o.getSecret(ctx, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if data, ok := secret.Data[ref.Key]; ok {
+			return data, nil
+		}
+
+		return nil, fmt.Errorf("key %s not found in secret %s", ref.Key, ref.Name)
+	default:
+		return nil, fmt.Errorf("one of configMapKeyRef or secretKeyRef is required")
+	}
+}
+
 func (o *StateObserver) getSecret(ctx context.Context, name string) (*corev1.Secret, error) {
 	secret := corev1.Secret{}
 	err := o.Client.Get(ctx, types.NamespacedName{
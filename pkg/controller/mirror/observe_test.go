@@ -190,3 +190,73 @@ func (s *ObserveTestSuite) TestStateObserver_observe_WithDefaults() {
 	// Verify that Defaulted() was called by checking that defaults have been applied
 	s.NotNil(observed.Mirror)
 }
+
+func (s *ObserveTestSuite) TestStateObserver_observe_ImagePolicies() {
+	observer := &StateObserver{
+		Client: s.client,
+		Request: ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      "test-mirror",
+				Namespace: "default",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	observed := NewObservedState()
+	err := observer.observe(ctx, observed)
+	s.NoError(err)
+
+	// Only the image policies in the namespace of the mirror apply.
+	s.Len(observed.ClusterImagePolicies, 1)
+	s.Equal("test-cluster-policy", observed.ClusterImagePolicies[0].Name)
+	s.Len(observed.ImagePolicies, 1)
+	s.Equal("test-policy", observed.ImagePolicies[0].Name)
+}
+
+func (s *ObserveTestSuite) TestStateObserver_observe_Transports() {
+	observer := &StateObserver{
+		Client: s.client,
+		Request: ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      "test-mirror-registries",
+				Namespace: "default",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	observed := NewObservedState()
+	err := observer.observe(ctx, observed)
+	s.NoError(err)
+	s.Len(observed.Transports, 3)
+
+	transport := observed.Transports["registry.example.com"]
+	s.Equal("configmap-ca", string(transport.CA))
+	s.Equal("client-cert", string(transport.Certificate))
+	s.Equal("client-key", string(transport.Key))
+	s.Equal("http://proxy.example.com:3128", transport.Proxy.String())
+	s.False(transport.Insecure)
+
+	s.Equal("secret-ca", string(observed.Transports["secret-ca.example.com"].CA))
+	s.True(observed.Transports["insecure.example.com"].Insecure)
+}
+
+func (s *ObserveTestSuite) TestStateObserver_observe_TransportsMissingCA() {
+	observer := &StateObserver{
+		Client: s.client,
+		Request: ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      "test-mirror-registries-missing",
+				Namespace: "default",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	observed := NewObservedState()
+	err := observer.observe(ctx, observed)
+	s.NoError(err)
+	s.Error(observed.TransportError)
+	s.Empty(observed.Transports)
+}
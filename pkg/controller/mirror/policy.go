@@ -0,0 +1,374 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/signature"
+)
+
+// Policy is the signature policy that the images are verified against when they are copied.
+type Policy struct {
+	signature *signature.Policy
+	lookaside map[string]string
+}
+
+// DefaultPolicy returns the policy that accepts any image.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		signature: &signature.Policy{
+			Default: signature.PolicyRequirements{signature.NewPRInsecureAcceptAnything()},
+		},
+		lookaside: make(map[string]string),
+	}
+}
+
+// NewPolicy combines the cluster image policies and the image policies of a namespace into
+// a single policy.  The requirements of the policies are combined so every policy must be
+// satisfied.  When more than one policy sets the signature storage for a scope, the cluster
+// policies take precedence.
+//
+// Only the most specific scope that matches an image is used when the image is verified, so
+// every scope carries the requirements of the scopes covering it in the other policies, or
+// their default if no scope covers it.  This keeps a narrower scope in one policy from
+// replacing the requirements of another.
+func NewPolicy(cluster []coralv1beta1.ClusterImagePolicy, namespaced []coralv1beta1.ImagePolicy) (*Policy, error) {
+	specs := make([]coralv1beta1.ImagePolicySpec, 0, len(cluster)+len(namespaced))
+	for _, p := range cluster {
+		specs = append(specs, p.Spec)
+	}
+	for _, p := range namespaced {
+		specs = append(specs, p.Spec)
+	}
+
+	policy := &Policy{
+		signature: &signature.Policy{
+			Default:    make(signature.PolicyRequirements, 0),
+			Transports: make(map[string]signature.PolicyTransportScopes),
+		},
+		lookaside: make(map[string]string),
+	}
+
+	layers := make([]policyLayer, 0, len(specs))
+	for _, spec := range specs {
+		layer, err := newPolicyLayer(spec)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+
+		policy.signature.Default = append(policy.signature.Default, layer.defaults...)
+
+		for _, scope := range spec.Scopes {
+			if _, ok := policy.lookaside[scope.Scope]; !ok && scope.Lookaside != "" {
+				policy.lookaside[scope.Scope] = scope.Lookaside
+			}
+		}
+	}
+
+	if len(policy.signature.Default) == 0 {
+		policy.signature.Default = signature.PolicyRequirements{signature.NewPRInsecureAcceptAnything()}
+	}
+
+	scopes := make(signature.PolicyTransportScopes)
+	for _, layer := range layers {
+		for scope := range layer.scopes {
+			if _, ok := scopes[scope]; ok {
+				continue
+			}
+
+			reqs := make(signature.PolicyRequirements, 0)
+			for _, l := range layers {
+				reqs = append(reqs, l.covering(scope)...)
+			}
+
+			// Scopes that are only covered by empty defaults accept any image, the same
+			// as the default of the policy would.
+			if len(reqs) == 0 {
+				reqs = signature.PolicyRequirements{signature.NewPRInsecureAcceptAnything()}
+			}
+			scopes[scope] = reqs
+		}
+	}
+
+	if len(scopes) > 0 {
+		policy.signature.Transports[docker.Transport.Name()] = scopes
+	}
+
+	return policy, nil
+}
+
+// policyLayer holds the requirements of a single image policy.
+type policyLayer struct {
+	defaults signature.PolicyRequirements
+	scopes   map[string]signature.PolicyRequirements
+}
+
+func newPolicyLayer(spec coralv1beta1.ImagePolicySpec) (policyLayer, error) {
+	defaults, err := requirements(spec.Default)
+	if err != nil {
+		return policyLayer{}, fmt.Errorf("invalid default: %w", err)
+	}
+
+	layer := policyLayer{
+		defaults: defaults,
+		scopes:   make(map[string]signature.PolicyRequirements),
+	}
+
+	for _, scope := range spec.Scopes {
+		if err := validateScope(scope.Scope); err != nil {
+			return policyLayer{}, fmt.Errorf("invalid scope %q: %w", scope.Scope, err)
+		}
+
+		if len(scope.Requirements) == 0 {
+			return policyLayer{}, fmt.Errorf("invalid scope %q: no requirements", scope.Scope)
+		}
+
+		reqs, err := requirements(scope.Requirements)
+		if err != nil {
+			return policyLayer{}, fmt.Errorf("invalid scope %q: %w", scope.Scope, err)
+		}
+		layer.scopes[scope.Scope] = append(layer.scopes[scope.Scope], reqs...)
+	}
+
+	return layer, nil
+}
+
+// covering returns the requirements that the policy applies to the images in the scope,
+// which are the requirements of the most specific scope of the policy covering it or the
+// default of the policy.
+func (l policyLayer) covering(scope string) signature.PolicyRequirements {
+	for _, s := range coveringScopes(scope) {
+		if reqs, ok := l.scopes[s]; ok {
+			return reqs
+		}
+	}
+
+	return l.defaults
+}
+
+// coveringScopes returns the scope and the scopes that cover it from the most to the least
+// specific, in the order that the docker transport matches the scopes of an image.
+func coveringScopes(scope string) []string {
+	if host, ok := strings.CutPrefix(scope, "*."); ok {
+		return append([]string{scope}, wildcardScopes(host)...)
+	}
+
+	scopes := []string{scope}
+
+	// A scope with a tag or digest is covered by its repository.
+	name := scope
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	if name != scope {
+		scopes = append(scopes, name)
+	}
+
+	for {
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+		scopes = append(scopes, name)
+	}
+
+	// The remaining name is the registry hostname, which is covered by the wildcard
+	// scopes of its parent domains without the port.
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+
+	return append(scopes, wildcardScopes(name)...)
+}
+
+// wildcardScopes returns the wildcard scopes matching the subdomains of the parent domains
+// of the host.
+func wildcardScopes(host string) []string {
+	var scopes []string
+	for {
+		i := strings.Index(host, ".")
+		if i < 0 {
+			return scopes
+		}
+		host = host[i+1:]
+		scopes = append(scopes, "*."+host)
+	}
+}
+
+// requirements converts the image policy requirements to signature policy requirements.
+func requirements(reqs []coralv1beta1.ImagePolicyRequirement) (signature.PolicyRequirements, error) {
+	out := make(signature.PolicyRequirements, 0, len(reqs))
+	for _, req := range reqs {
+		var pr signature.PolicyRequirement
+		var err error
+
+		switch req.Type {
+		case coralv1beta1.ImagePolicyAcceptAnything:
+			pr = signature.NewPRInsecureAcceptAnything()
+		case coralv1beta1.ImagePolicyReject:
+			pr = signature.NewPRReject()
+		case coralv1beta1.ImagePolicySignedBy:
+			if err = validateGPGKeys(req.KeyData); err == nil {
+				pr, err = signature.NewPRSignedByKeyData(signature.SBKeyTypeGPGKeys, []byte(req.KeyData), signature.NewPRMMatchRepoDigestOrExact())
+			}
+		case coralv1beta1.ImagePolicySigstoreSigned:
+			if err = validatePublicKey(req.KeyData); err == nil {
+				pr, err = signature.NewPRSigstoreSignedKeyData([]byte(req.KeyData), signature.NewPRMMatchRepoDigestOrExact())
+			}
+		default:
+			err = fmt.Errorf("unknown requirement type %q", req.Type)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s requirement: %w", req.Type, err)
+		}
+		out = append(out, pr)
+	}
+
+	return out, nil
+}
+
+// validateScope checks that the scope is a fully qualified registry, registry namespace or
+// repository.  Scopes that are not fully qualified would never match an image.
+func validateScope(scope string) error {
+	if host, ok := strings.CutPrefix(scope, "*."); ok {
+		if strings.Contains(host, "/") {
+			return errors.New("wildcards are only supported for registry hostnames")
+		}
+		scope = host
+	}
+
+	// Registries are validated as the domain of a repository.
+	if !strings.Contains(scope, "/") {
+		scope += "/scope"
+	}
+
+	named, err := reference.ParseNormalizedNamed(scope)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(scope, reference.Domain(named)+"/") {
+		return errors.New("scope must include the registry hostname")
+	}
+
+	return nil
+}
+
+// validateGPGKeys checks that the keyring contains at least one GPG key.
+func validateGPGKeys(keyData string) error {
+	mech, keys, err := signature.NewEphemeralGPGSigningMechanism([]byte(keyData))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = mech.Close()
+	}()
+
+	if len(keys) == 0 {
+		return errors.New("no GPG keys found in keyData")
+	}
+
+	return nil
+}
+
+// validatePublicKey checks that the key data is a PEM encoded public key.
+func validatePublicKey(keyData string) error {
+	block, _ := pem.Decode([]byte(keyData))
+	if block == nil {
+		return errors.New("keyData is not PEM encoded")
+	}
+
+	if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return fmt.Errorf("keyData is not a public key: %w", err)
+	}
+
+	return nil
+}
+
+// registriesDir writes the signature storage configuration for the policy to a temporary
+// directory in the registries.d format.  Sigstore signatures are read from the registries
+// as attachments and GPG signatures from the lookaside storage of their scope.  The
+// returned function removes the directory.
+func (p *Policy) registriesDir() (string, func(), error) {
+	type namespace struct {
+		Lookaside              string `json:"lookaside,omitempty"`
+		UseSigstoreAttachments bool   `json:"use-sigstore-attachments"`
+	}
+
+	config := struct {
+		DefaultDocker namespace            `json:"default-docker"`
+		Docker        map[string]namespace `json:"docker,omitempty"`
+	}{
+		DefaultDocker: namespace{UseSigstoreAttachments: true},
+		Docker:        make(map[string]namespace),
+	}
+
+	for scope, lookaside := range p.lookaside {
+		config.Docker[scope] = namespace{
+			Lookaside:              lookaside,
+			UseSigstoreAttachments: true,
+		}
+	}
+
+	// YAML is a superset of JSON, so the configuration is written as JSON.
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "coral-registries.d-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	cleanup := func() {
+		_ = os.RemoveAll(dir)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "coral.yaml"), data, 0o600); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return dir, cleanup, nil
+}
+
+// rejection returns the reason that the image policy refused the image, or an empty
+// string if the error is not a policy rejection.
+func rejection(err error) string {
+	var rejected signature.PolicyRequirementError
+	if errors.As(err, &rejected) {
+		return rejected.Error()
+	}
+
+	return ""
+}
@@ -0,0 +1,236 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"github.com/containers/image/v5/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImagePolicySpec(scope string, reqs ...coralv1beta1.ImagePolicyRequirement) coralv1beta1.ImagePolicySpec {
+	return coralv1beta1.ImagePolicySpec{
+		Scopes: []coralv1beta1.ImagePolicyScope{
+			{Scope: scope, Requirements: reqs},
+		},
+	}
+}
+
+func testPublicKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestNewPolicy(t *testing.T) {
+	reject := coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicyReject}
+	accept := coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicyAcceptAnything}
+	sigstore := coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicySigstoreSigned, KeyData: testPublicKey(t)}
+
+	cluster := []coralv1beta1.ClusterImagePolicy{
+		{Spec: testImagePolicySpec("quay.io", sigstore)},
+	}
+	cluster[0].Spec.Scopes[0].Lookaside = "https://sigs.example.com/cluster"
+
+	namespaced := []coralv1beta1.ImagePolicy{
+		{Spec: testImagePolicySpec("quay.io", reject)},
+		{Spec: testImagePolicySpec("docker.io/library/nginx", accept)},
+	}
+	namespaced[0].Spec.Scopes[0].Lookaside = "https://sigs.example.com/namespace"
+
+	policy, err := NewPolicy(cluster, namespaced)
+	require.NoError(t, err)
+
+	// Images that do not match a scope are accepted.
+	assert.Equal(t, signature.PolicyRequirements{signature.NewPRInsecureAcceptAnything()}, policy.signature.Default)
+
+	scopes := policy.signature.Transports["docker"]
+	assert.Len(t, scopes, 2)
+	assert.Len(t, scopes["quay.io"], 2, "the requirements of every policy must be satisfied")
+	assert.Len(t, scopes["docker.io/library/nginx"], 1)
+
+	assert.Equal(t, map[string]string{"quay.io": "https://sigs.example.com/cluster"}, policy.lookaside)
+}
+
+func TestNewPolicy_default(t *testing.T) {
+	policy, err := NewPolicy(nil, []coralv1beta1.ImagePolicy{
+		{Spec: coralv1beta1.ImagePolicySpec{
+			Default: []coralv1beta1.ImagePolicyRequirement{{Type: coralv1beta1.ImagePolicyReject}},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, signature.PolicyRequirements{signature.NewPRReject()}, policy.signature.Default)
+	assert.Empty(t, policy.signature.Transports)
+}
+
+func TestNewPolicy_cluster_floor(t *testing.T) {
+	reject := coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicyReject}
+	accept := coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicyAcceptAnything}
+	sigstore := coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicySigstoreSigned, KeyData: testPublicKey(t)}
+
+	clusterSpec := testImagePolicySpec("docker.io", sigstore)
+	clusterSpec.Default = []coralv1beta1.ImagePolicyRequirement{reject}
+
+	namespacedSpec := coralv1beta1.ImagePolicySpec{
+		Scopes: []coralv1beta1.ImagePolicyScope{
+			{Scope: "docker.io/library/nginx", Requirements: []coralv1beta1.ImagePolicyRequirement{accept}},
+			{Scope: "quay.io/app", Requirements: []coralv1beta1.ImagePolicyRequirement{accept}},
+		},
+	}
+
+	policy, err := NewPolicy(
+		[]coralv1beta1.ClusterImagePolicy{{Spec: clusterSpec}},
+		[]coralv1beta1.ImagePolicy{{Spec: namespacedSpec}},
+	)
+	require.NoError(t, err)
+
+	signedBy, err := requirements([]coralv1beta1.ImagePolicyRequirement{sigstore})
+	require.NoError(t, err)
+
+	scopes := policy.signature.Transports["docker"]
+	assert.Len(t, scopes, 3)
+
+	// The narrower namespaced scope keeps the requirements of the cluster scope covering it.
+	assert.Equal(t, signature.PolicyRequirements{signedBy[0], signature.NewPRInsecureAcceptAnything()}, scopes["docker.io/library/nginx"])
+	// Namespaced scopes that no cluster scope covers keep the cluster default.
+	assert.Equal(t, signature.PolicyRequirements{signature.NewPRReject(), signature.NewPRInsecureAcceptAnything()}, scopes["quay.io/app"])
+	assert.Equal(t, signedBy, scopes["docker.io"])
+}
+
+func TestCoveringScopes(t *testing.T) {
+	tests := []struct {
+		scope    string
+		expected []string
+	}{
+		{
+			scope:    "docker.io/library/nginx:1.27",
+			expected: []string{"docker.io/library/nginx:1.27", "docker.io/library/nginx", "docker.io/library", "docker.io", "*.io"},
+		},
+		{
+			scope:    "registry.example.com/app@sha256:0123",
+			expected: []string{"registry.example.com/app@sha256:0123", "registry.example.com/app", "registry.example.com", "*.example.com", "*.com"},
+		},
+		{
+			scope:    "localhost:5000/app",
+			expected: []string{"localhost:5000/app", "localhost:5000"},
+		},
+		{
+			scope:    "registry.example.com:5000/app:v1",
+			expected: []string{"registry.example.com:5000/app:v1", "registry.example.com:5000/app", "registry.example.com:5000", "*.example.com", "*.com"},
+		},
+		{
+			scope:    "*.example.com",
+			expected: []string{"*.example.com", "*.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			assert.Equal(t, tt.expected, coveringScopes(tt.scope))
+		})
+	}
+}
+
+func TestNewPolicy_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec coralv1beta1.ImagePolicySpec
+	}{
+		{
+			name: "invalid scope",
+			spec: testImagePolicySpec("INVALID/Scope", coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicyReject}),
+		},
+		{
+			name: "scope without requirements",
+			spec: testImagePolicySpec("quay.io"),
+		},
+		{
+			name: "signedBy without a key",
+			spec: testImagePolicySpec("quay.io", coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicySignedBy}),
+		},
+		{
+			name: "scope without a registry",
+			spec: testImagePolicySpec("library/nginx", coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicyReject}),
+		},
+		{
+			name: "wildcard repository",
+			spec: testImagePolicySpec("*.example.com/app", coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicyReject}),
+		},
+		{
+			name: "signedBy without a GPG key",
+			spec: testImagePolicySpec("quay.io", coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicySignedBy, KeyData: "not a key"}),
+		},
+		{
+			name: "sigstoreSigned with an invalid key",
+			spec: testImagePolicySpec("quay.io", coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicySigstoreSigned, KeyData: "not a key"}),
+		},
+		{
+			name: "sigstoreSigned without a key",
+			spec: testImagePolicySpec("quay.io", coralv1beta1.ImagePolicyRequirement{Type: coralv1beta1.ImagePolicySigstoreSigned}),
+		},
+		{
+			name: "unknown requirement type",
+			spec: coralv1beta1.ImagePolicySpec{
+				Default: []coralv1beta1.ImagePolicyRequirement{{Type: "Unknown"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy([]coralv1beta1.ClusterImagePolicy{{Spec: tt.spec}}, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPolicy_registriesDir(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.lookaside["quay.io"] = "https://sigs.example.com"
+
+	dir, cleanup, err := policy.registriesDir()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "coral.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"quay.io":{"lookaside":"https://sigs.example.com","use-sigstore-attachments":true}`)
+
+	cleanup()
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRejection(t *testing.T) {
+	err := fmt.Errorf("failed to copy: Source image rejected: %w", signature.PolicyRequirementError("Running image docker://quay.io/app:latest is rejected by policy."))
+	assert.Equal(t, "Running image docker://quay.io/app:latest is rejected by policy.", rejection(err))
+
+	assert.Empty(t, rejection(fmt.Errorf("failed to copy: connection refused")))
+}
@@ -19,10 +19,11 @@ import (
 	"fmt"
 	goruntime "runtime"
 
-	utilauth "ctx.sh/coral/pkg/agent/watcher/imagesync"
 	"ctx.sh/coral/pkg/util"
+	utilauth "ctx.sh/coral/pkg/util/auth"
 	"github.com/containers/image/v5/copy"
 	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/manifest"
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/types"
 	corev1 "k8s.io/api/core/v1"
@@ -30,16 +31,28 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// Copied describes the manifest that was copied to the destination registry.
+type Copied struct {
+	// Digest is the digest of the copied manifest.
+	Digest string
+	// Platforms are the platforms of the copied images.
+	Platforms []string
+}
+
 type Synchronizer struct {
-	secrets []corev1.Secret
-	dst     string
-	copyAll bool
+	secrets    []corev1.Secret
+	dst        string
+	copyAll    bool
+	policy     *Policy
+	transports map[string]Transport
 }
 
 func NewSynchronizer() *Synchronizer {
 	return &Synchronizer{
-		copyAll: false,
-		secrets: make([]corev1.Secret, 0),
+		copyAll:    false,
+		secrets:    make([]corev1.Secret, 0),
+		policy:     DefaultPolicy(),
+		transports: make(map[string]Transport),
 	}
 }
 
@@ -48,6 +61,18 @@ func (s *Synchronizer) WithDestinationRegistry(dst string) *Synchronizer {
 	return s
 }
 
+// WithPolicy sets the signature policy that the images are verified against.
+func (s *Synchronizer) WithPolicy(policy *Policy) *Synchronizer {
+	s.policy = policy
+	return s
+}
+
+// WithTransports sets the connection configuration for the registry hosts.
+func (s *Synchronizer) WithTransports(transports map[string]Transport) *Synchronizer {
+	s.transports = transports
+	return s
+}
+
 func (s *Synchronizer) WithImagePullSecrets(secrets []corev1.Secret) *Synchronizer {
 	s.secrets = append(s.secrets, secrets...)
 	return s
@@ -58,11 +83,75 @@ func (s *Synchronizer) WithCopyAll(copyAll bool) *Synchronizer {
 	return s
 }
 
-func (s *Synchronizer) Copy(ctx context.Context, image string) error {
+// Destination returns the reference of the copy of the image in the destination registry.
+func (s *Synchronizer) Destination(image string) (string, error) {
+	ref, err := util.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	return s.dst + "/" + ref.Name(), nil
+}
+
+// SourceDigest returns the digest of the manifest of the image in the source registry.  Only
+// the manifest is requested so that it is cheap to check for changes.
+func (s *Synchronizer) SourceDigest(ctx context.Context, image string) (string, error) {
+	ref, err := util.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+
+	return s.digest(ctx, ref.String())
+}
+
+// DestinationDigest returns the digest of the manifest of the copy of the image in the
+// destination registry.
+func (s *Synchronizer) DestinationDigest(ctx context.Context, image string) (string, error) {
+	dst, err := s.Destination(image)
+	if err != nil {
+		return "", err
+	}
+
+	return s.digest(ctx, dst)
+}
+
+func (s *Synchronizer) digest(ctx context.Context, image string) (string, error) {
+	authProvider, err := utilauth.NewAuth(s.secrets)
+	if err != nil {
+		return "", fmt.Errorf("failed to create auth: %w", err)
+	}
+
+	ref, err := docker.ParseReference("//" + image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse reference: %w", err)
+	}
+
+	certDir, cleanup, err := certsDir(s.transports)
+	if err != nil {
+		return "", fmt.Errorf("failed to write certificates: %w", err)
+	}
+	defer cleanup()
+
+	digest, err := docker.GetDigest(ctx, s.createSystemContext(ctx, image, authProvider, certDir), ref)
+	if err != nil {
+		return "", err
+	}
+
+	return digest.String(), nil
+}
+
+// Copy copies the image to the destination registry and returns the digest and platforms of
+// the copied manifest.
+func (s *Synchronizer) Copy(ctx context.Context, image string) (*Copied, error) {
 	logger := log.FromContext(ctx)
 
-	srcImage := util.GetImageQualifiedName(util.DefaultSearchRegistry, image)
-	dstImage := s.dst + "/" + util.ExtractImageName(image)
+	ref, err := util.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	srcImage := ref.String()
+	dstImage, _ := s.Destination(image)
 
 	logger.V(4).Info("copying mirror image", "src", srcImage, "dst", dstImage)
 
@@ -70,31 +159,41 @@ func (s *Synchronizer) Copy(ctx context.Context, image string) error {
 	authProvider, err := utilauth.NewAuth(s.secrets)
 	if err != nil {
 		logger.Error(err, "failed to create auth")
-		return fmt.Errorf("failed to create auth: %w", err)
+		return nil, fmt.Errorf("failed to create auth: %w", err)
 	}
 
+	certDir, cleanup, err := certsDir(s.transports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write certificates: %w", err)
+	}
+	defer cleanup()
+
 	// Create system context
-	srcCtx := s.createSystemContext(ctx, srcImage, authProvider)
-	dstCtx := s.createSystemContext(ctx, dstImage, authProvider)
+	srcCtx := s.createSystemContext(ctx, srcImage, authProvider, certDir)
+	dstCtx := s.createSystemContext(ctx, dstImage, authProvider, certDir)
 
 	// Create source image reference
 	srcRef, err := docker.ParseReference("//" + srcImage)
 	if err != nil {
-		return fmt.Errorf("failed to parse source reference: %w", err)
+		return nil, fmt.Errorf("failed to parse source reference: %w", err)
 	}
 
 	dstRef, err := docker.ParseReference("//" + dstImage)
 	if err != nil {
-		return fmt.Errorf("failed to parse destination reference: %w", err)
+		return nil, fmt.Errorf("failed to parse destination reference: %w", err)
 	}
 
-	// TODO(rob): Make me configurable.
-	policyCtx, err := signature.NewPolicyContext(&signature.Policy{
-		Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()},
-	})
+	// The signatures are read from the source using the signature storage of the policy.
+	registriesDir, cleanup, err := s.policy.registriesDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure signature storage: %w", err)
+	}
+	defer cleanup()
+	srcCtx.RegistriesDirPath = registriesDir
 
+	policyCtx, err := signature.NewPolicyContext(s.policy.signature)
 	if err != nil {
-		return fmt.Errorf("failed to create policy context: %w", err)
+		return nil, fmt.Errorf("failed to create policy context: %w", err)
 	}
 	defer func() {
 		_ = policyCtx.Destroy()
@@ -114,26 +213,84 @@ func (s *Synchronizer) Copy(ctx context.Context, image string) error {
 		srcCtx.ArchitectureChoice = goruntime.GOARCH
 	}
 
-	// Perform the copy with configurable multi-arch support
-	_, err = copy.Image(ctx, policyCtx, dstRef, srcRef, &copy.Options{
+	// Perform the copy with configurable multi-arch support.  The signatures are verified
+	// against the policy, but are not copied to the coral registry.
+	copied, err := copy.Image(ctx, policyCtx, dstRef, srcRef, &copy.Options{
 		SourceCtx:          srcCtx,
 		DestinationCtx:     dstCtx,
 		ImageListSelection: imageListSelection,
+		RemoveSignatures:   true,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to copy %s: %w", logMsg, err)
+		return nil, fmt.Errorf("failed to copy %s: %w", logMsg, err)
 	}
 
-	return nil
+	digest, err := manifest.Digest(copied)
+	if err != nil {
+		return nil, fmt.Errorf("failed to digest the copied manifest: %w", err)
+	}
+
+	return &Copied{
+		Digest:    digest.String(),
+		Platforms: s.platforms(copied),
+	}, nil
 }
 
-// createSystemContext creates a system context for containers/image operations.
-func (s *Synchronizer) createSystemContext(ctx context.Context, image string, authProvider *utilauth.Auth) *types.SystemContext {
+// platforms returns the platforms of the copied manifest.  The platforms are read from the
+// manifest list, or are the system platform when only that was copied.
+func (s *Synchronizer) platforms(blob []byte) []string {
+	mimeType := manifest.GuessMIMEType(blob)
+	if !manifest.MIMETypeIsMultiImage(mimeType) {
+		if s.copyAll {
+			return nil
+		}
+		return []string{goruntime.GOOS + "/" + goruntime.GOARCH}
+	}
+
+	list, err := manifest.ListFromBlob(blob, mimeType)
+	if err != nil {
+		return nil
+	}
+
+	platforms := make([]string, 0)
+	for _, instance := range list.Instances() {
+		update, err := list.Instance(instance)
+		if err != nil || update.ReadOnly.Platform == nil {
+			continue
+		}
+
+		platform := update.ReadOnly.Platform
+		name := platform.OS + "/" + platform.Architecture
+		if platform.Variant != "" {
+			name += "/" + platform.Variant
+		}
+		platforms = append(platforms, name)
+	}
+
+	return platforms
+}
+
+// createSystemContext creates a system context for containers/image operations.  The registry
+// certificate is verified unless the registry is configured as insecure.  The coral registry
+// is always treated as insecure as it is served over plain HTTP.
+func (s *Synchronizer) createSystemContext(ctx context.Context, image string, authProvider *utilauth.Auth, certDir string) *types.SystemContext {
 	logger := ctrl.LoggerFrom(ctx)
 
+	host := util.DefaultSearchRegistry
+	if ref, err := util.ParseReference(image); err == nil {
+		host = ref.Domain()
+	}
+	transport := s.transports[host]
+
+	insecure := types.OptionalBoolFalse
+	if transport.Insecure || host == s.dst {
+		insecure = types.OptionalBoolTrue
+	}
+
 	systemCtx := &types.SystemContext{
-		// TODO: configurable
-		DockerInsecureSkipTLSVerify: types.OptionalBoolTrue,
+		DockerInsecureSkipTLSVerify: insecure,
+		DockerPerHostCertDirPath:    certDir,
+		DockerProxyURL:              transport.Proxy,
 		// Disable Docker daemon - we're doing registry-to-registry operations (says claude, I dont believe it)
 		DockerDaemonHost: "",
 	}
@@ -144,11 +301,10 @@ func (s *Synchronizer) createSystemContext(ctx context.Context, image string, au
 
 		// For now, we'll use a simple approach and configure auth for the source registry
 		// This is simpler than the callback-based approach
-		registry := util.ExtractImageHostname(image)
-		authConfigs := authProvider.Lookup(registry)
+		authConfigs := authProvider.Lookup(host)
 		if len(authConfigs) > 0 {
 			authConfig := authConfigs[0]
-			logger.V(6).Info("found auth config for registry", "registry", registry, "username", authConfig.Username)
+			logger.V(6).Info("found auth config for registry", "registry", host, "username", authConfig.Username)
 
 			systemCtx.DockerAuthConfig = &types.DockerAuthConfig{
 				Username: authConfig.Username,
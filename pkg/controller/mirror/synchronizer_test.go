@@ -16,6 +16,7 @@ package mirror
 
 import (
 	"context"
+	"net/url"
 	"testing"
 
 	"github.com/containers/image/v5/types"
@@ -177,47 +178,65 @@ func TestSynchronizer_ChainedConfiguration(t *testing.T) {
 }
 
 func TestSynchronizer_createSystemContext(t *testing.T) {
+	proxy, err := url.Parse("http://proxy.example.com:3128")
+	require.NoError(t, err)
+
+	transports := map[string]Transport{
+		"insecure.example.com": {Insecure: true},
+		"proxied.example.com":  {Proxy: proxy},
+		"localhost":            {Insecure: true},
+	}
+
 	tests := []struct {
-		name         string
-		image        string
-		authProvider interface{} // Using interface{} since we can't easily create utilauth.Auth
-		expectAuth   bool
+		name           string
+		image          string
+		expectInsecure types.OptionalBool
+		expectProxy    *url.URL
 	}{
 		{
-			name:         "docker.io image without auth",
-			image:        "docker.io/library/nginx:latest",
-			authProvider: nil,
-			expectAuth:   false,
+			name:           "docker.io image is verified",
+			image:          "docker.io/library/nginx:latest",
+			expectInsecure: types.OptionalBoolFalse,
+		},
+		{
+			name:           "insecure registry",
+			image:          "insecure.example.com/nginx:latest",
+			expectInsecure: types.OptionalBoolTrue,
 		},
 		{
-			name:         "custom registry without auth",
-			image:        "registry.example.com/nginx:latest",
-			authProvider: nil,
-			expectAuth:   false,
+			name:           "registry behind a proxy",
+			image:          "proxied.example.com/nginx:latest",
+			expectInsecure: types.OptionalBoolFalse,
+			expectProxy:    proxy,
 		},
 		{
-			name:         "localhost registry without auth",
-			image:        "localhost:5000/nginx:latest",
-			authProvider: nil,
-			expectAuth:   false,
+			name:           "registry without a dot in the hostname",
+			image:          "localhost/nginx",
+			expectInsecure: types.OptionalBoolTrue,
+		},
+		{
+			name:           "coral registry is insecure",
+			image:          "localhost:5000/nginx:latest",
+			expectInsecure: types.OptionalBoolTrue,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewSynchronizer()
+			s := NewSynchronizer().
+				WithDestinationRegistry("localhost:5000").
+				WithTransports(transports)
 			ctx := context.Background()
 
 			// Since utilauth.Auth is complex to mock, we'll test the nil case
-			systemCtx := s.createSystemContext(ctx, tt.image, nil)
+			systemCtx := s.createSystemContext(ctx, tt.image, nil, "/tmp/certs.d")
 
 			require.NotNil(t, systemCtx)
-			assert.Equal(t, types.OptionalBoolTrue, systemCtx.DockerInsecureSkipTLSVerify)
+			assert.Equal(t, tt.expectInsecure, systemCtx.DockerInsecureSkipTLSVerify)
+			assert.Equal(t, tt.expectProxy, systemCtx.DockerProxyURL)
+			assert.Equal(t, "/tmp/certs.d", systemCtx.DockerPerHostCertDirPath)
 			assert.Empty(t, systemCtx.DockerDaemonHost, "Docker daemon should be disabled")
-
-			if !tt.expectAuth {
-				assert.Nil(t, systemCtx.DockerAuthConfig)
-			}
+			assert.Nil(t, systemCtx.DockerAuthConfig)
 		})
 	}
 }
@@ -253,7 +272,7 @@ func TestSynchronizer_Copy_ValidationErrors(t *testing.T) {
 			s := tt.setupSync()
 			ctx := context.Background()
 
-			err := s.Copy(ctx, tt.image)
+			_, err := s.Copy(ctx, tt.image)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -0,0 +1,90 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// dockerHost is the host that images on docker.io are pulled from.  The certificates for
+// docker.io are looked up under this host.
+const dockerHost = "registry-1.docker.io"
+
+// Transport is the connection configuration for a registry host.
+type Transport struct {
+	// CA is the PEM encoded CA bundle that is trusted in addition to the system CAs.
+	CA []byte
+	// Certificate and Key are the PEM encoded client certificate and key.
+	Certificate []byte
+	Key         []byte
+	// Insecure disables the verification of the registry certificate.
+	Insecure bool
+	// Proxy is the proxy that is used to connect to the registry.
+	Proxy *url.URL
+}
+
+// certsDir writes the CA bundles and client certificates to a temporary directory with a
+// subdirectory for each host, using the layout of /etc/docker/certs.d.  No directory is
+// created when none of the hosts have certificates.  The returned function removes the
+// directory.
+func certsDir(transports map[string]Transport) (string, func(), error) {
+	noop := func() {}
+
+	files := make(map[string][]byte)
+	for host, t := range transports {
+		if host == "docker.io" {
+			host = dockerHost
+		}
+
+		if len(t.CA) > 0 {
+			files[filepath.Join(host, "ca.crt")] = t.CA
+		}
+
+		if len(t.Certificate) > 0 && len(t.Key) > 0 {
+			files[filepath.Join(host, "client.cert")] = t.Certificate
+			files[filepath.Join(host, "client.key")] = t.Key
+		}
+	}
+
+	if len(files) == 0 {
+		return "", noop, nil
+	}
+
+	dir, err := os.MkdirTemp("", "coral-certs.d-")
+	if err != nil {
+		return "", noop, err
+	}
+
+	cleanup := func() {
+		_ = os.RemoveAll(dir)
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			cleanup()
+			return "", noop, err
+		}
+
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			cleanup()
+			return "", noop, err
+		}
+	}
+
+	return dir, cleanup, nil
+}
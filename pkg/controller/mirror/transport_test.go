@@ -0,0 +1,65 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertsDir(t *testing.T) {
+	dir, cleanup, err := certsDir(map[string]Transport{
+		"registry.example.com:5000": {
+			CA:          []byte("ca"),
+			Certificate: []byte("cert"),
+			Key:         []byte("key"),
+		},
+		"docker.io":            {CA: []byte("docker")},
+		"insecure.example.com": {Insecure: true},
+	})
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"registry.example.com:5000/ca.crt":      "ca",
+		"registry.example.com:5000/client.cert": "cert",
+		"registry.example.com:5000/client.key":  "key",
+		"registry-1.docker.io/ca.crt":           "docker",
+	}
+	for name, expected := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "insecure.example.com"))
+	assert.True(t, os.IsNotExist(err))
+
+	cleanup()
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCertsDir_empty(t *testing.T) {
+	dir, cleanup, err := certsDir(map[string]Transport{
+		"insecure.example.com": {Insecure: true},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, dir)
+	cleanup()
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+type Options struct{}
+
+// Controller generates an imagesync for each of the workloads of a kind that opt in with the
+// imagesync.coral.ctx.sh/enabled annotation.  The imagesync is owned by the workload and is
+// garbage collected with it.
+type Controller struct {
+	Scheme    *runtime.Scheme
+	GroupKind schema.GroupKind
+	client.Client
+}
+
+// SetupWithManager registers a controller for each of the workload kinds.
+func SetupWithManager(mgr ctrl.Manager, opts *Options) error {
+	for _, gk := range util.WorkloadKinds() {
+		c := &Controller{
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			GroupKind: gk,
+		}
+
+		obj, err := c.newObject()
+		if err != nil {
+			return err
+		}
+
+		if err := ctrl.NewControllerManagedBy(mgr).
+			Named("workload-"+strings.ToLower(gk.Kind)).
+			For(obj, builder.WithPredicates(enabledPredicate())).
+			Owns(&coralv1beta1.ImageSync{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+			Complete(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// +kubebuilder:rbac:groups=apps,resources=daemonsets;deployments;replicasets;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs;jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods;replicationcontrollers,verbs=get;list;watch
+
+func (c *Controller) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	logger.V(4).Info("reconciling workload", "kind", c.GroupKind.Kind, "request", req)
+
+	obj, err := c.newObject()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := c.Get(ctx, req.NamespacedName, obj); err != nil {
+		// The generated imagesync is garbage collected through the owner reference.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	isync := &coralv1beta1.ImageSync{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GeneratedName(c.GroupKind.Kind, obj.GetName()),
+			Namespace: obj.GetNamespace(),
+		},
+	}
+
+	spec, ok := c.generate(obj)
+	if !ok || !obj.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, c.cleanup(ctx, obj, isync)
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, c.Client, isync, func() error {
+		if !isync.CreationTimestamp.IsZero() && !metav1.IsControlledBy(isync, obj) {
+			return fmt.Errorf("imagesync %s/%s is not owned by the workload", isync.Namespace, isync.Name)
+		}
+
+		isync.Spec = spec
+		coralv1beta1.Defaulted(isync)
+
+		labels := isync.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[coralv1beta1.ImageSyncGeneratedLabel] = "true"
+		isync.SetLabels(labels)
+
+		return controllerutil.SetControllerReference(obj, isync, c.Scheme)
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.V(4).Info("generated imagesync", "name", isync.Name, "result", result)
+
+	return ctrl.Result{}, nil
+}
+
+// generate returns the imagesync spec for the workload.  False is returned if the workload
+// is not enabled, is managed by another workload or does not have any images to sync.
+func (c *Controller) generate(obj client.Object) (coralv1beta1.ImageSyncSpec, bool) {
+	if obj.GetAnnotations()[coralv1beta1.ImageSyncEnableAnnotation] != "true" || c.managed(obj) {
+		return coralv1beta1.ImageSyncSpec{}, false
+	}
+
+	spec := Generate(obj)
+
+	return spec, len(spec.Images) > 0
+}
+
+// managed returns true if the workload is controlled by another workload kind, such as the
+// replicasets of a deployment.  The owning workload generates the imagesync instead.
+func (c *Controller) managed(obj client.Object) bool {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil {
+		return false
+	}
+
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return false
+	}
+
+	_, err = util.GroupKindToType(gv.WithKind(owner.Kind).GroupKind())
+	return err == nil
+}
+
+// cleanup removes a previously generated imagesync when the workload no longer opts in.
+func (c *Controller) cleanup(ctx context.Context, obj client.Object, isync *coralv1beta1.ImageSync) error {
+	if err := c.Get(ctx, client.ObjectKeyFromObject(isync), isync); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !metav1.IsControlledBy(isync, obj) {
+		return nil
+	}
+
+	if err := c.Delete(ctx, isync); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Controller) newObject() (client.Object, error) {
+	t, err := util.GroupKindToType(c.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := t.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("unsupported group kind %v", c.GroupKind)
+	}
+
+	return obj, nil
+}
+
+// GeneratedName returns the name of the imagesync generated for the workload.  Names that
+// would be too long are truncated and suffixed with a hash of the full name.
+func GeneratedName(kind, name string) string {
+	generated := strings.ToLower(kind) + "-" + name
+	if len(generated) <= validation.DNS1123SubdomainMaxLength {
+		return generated
+	}
+
+	sum := sha256.Sum256([]byte(generated))
+	suffix := "-" + hex.EncodeToString(sum[:])[:8]
+
+	return generated[:validation.DNS1123SubdomainMaxLength-len(suffix)] + suffix
+}
+
+// enabledPredicate filters the workloads to those that are, or were, enabled so that the
+// generated imagesync can be removed when the annotation is dropped.
+func enabledPredicate() predicate.Funcs {
+	enabled := func(obj client.Object) bool {
+		return obj.GetAnnotations()[coralv1beta1.ImageSyncEnableAnnotation] == "true"
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return enabled(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return enabled(e.ObjectOld) || enabled(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return enabled(e.Object)
+		},
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/mock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+type ControllerTestSuite struct {
+	client *mock.Client
+	suite.Suite
+}
+
+func (s *ControllerTestSuite) SetupTest() {
+	logger := zap.New(zap.UseDevMode(true), zap.Level(zapcore.Level(-8)))
+	log.SetLogger(logger)
+
+	s.client = mock.NewClient().
+		WithLogger(logger).
+		WithFixtureDirectory(filepath.Join("..", "..", "..", "fixtures"))
+
+	s.client.ApplyFixtureOrDie("workload-controller.yaml")
+}
+
+func (s *ControllerTestSuite) TearDownTest() {
+	s.client.Reset()
+}
+
+func TestControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ControllerTestSuite))
+}
+
+func (s *ControllerTestSuite) controller(kind string) *Controller {
+	return &Controller{
+		Client:    s.client,
+		Scheme:    s.client.Scheme(),
+		GroupKind: appsv1.SchemeGroupVersion.WithKind(kind).GroupKind(),
+	}
+}
+
+func (s *ControllerTestSuite) reconcile(kind, name string) {
+	_, err := s.controller(kind).Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Namespace: "default", Name: name},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *ControllerTestSuite) TestReconcile_generate() {
+	ctx := context.Background()
+	s.reconcile("Deployment", "web")
+
+	var isync coralv1beta1.ImageSync
+	err := s.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "deployment-web"}, &isync)
+	s.Require().NoError(err)
+
+	s.Equal([]string{"busybox:1.36", "nginx:1.27"}, isync.Spec.Images)
+	s.Equal([]coralv1beta1.NodeSelector{
+		{Key: "role", Operator: selection.In, Values: []string{"app"}},
+	}, isync.Spec.NodeSelector)
+	s.Equal([]corev1.LocalObjectReference{{Name: "fake-credentials"}}, isync.Spec.ImagePullSecrets)
+	s.Len(isync.Spec.Tolerations, 1)
+	s.Equal("true", isync.Labels[coralv1beta1.ImageSyncGeneratedLabel])
+
+	owner := metav1.GetControllerOf(&isync)
+	s.Require().NotNil(owner)
+	s.Equal("Deployment", owner.Kind)
+	s.Equal("web", owner.Name)
+
+	// Template changes are reflected in the generated imagesync.
+	var deployment appsv1.Deployment
+	err = s.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web"}, &deployment)
+	s.Require().NoError(err)
+
+	deployment.Spec.Template.Spec.Containers[0].Image = "nginx:1.28"
+	s.Require().NoError(s.client.Update(ctx, &deployment))
+	s.reconcile("Deployment", "web")
+
+	err = s.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "deployment-web"}, &isync)
+	s.Require().NoError(err)
+	s.Equal([]string{"busybox:1.36", "nginx:1.28"}, isync.Spec.Images)
+
+	// Dropping the annotation removes the generated imagesync.
+	delete(deployment.Annotations, coralv1beta1.ImageSyncEnableAnnotation)
+	s.Require().NoError(s.client.Update(ctx, &deployment))
+	s.reconcile("Deployment", "web")
+
+	err = s.client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "deployment-web"}, &isync)
+	s.True(errors.IsNotFound(err))
+}
+
+func (s *ControllerTestSuite) TestReconcile_skipped() {
+	tests := []struct {
+		name     string
+		kind     string
+		workload string
+	}{
+		{name: "not enabled", kind: "Deployment", workload: "plain"},
+		{name: "managed by another workload", kind: "ReplicaSet", workload: "web-5d8f7c9b6"},
+		{name: "not found", kind: "Deployment", workload: "missing"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.reconcile(tt.kind, tt.workload)
+
+			var isyncs coralv1beta1.ImageSyncList
+			s.Require().NoError(s.client.List(context.Background(), &isyncs))
+			s.Empty(isyncs.Items)
+		})
+	}
+}
+
+func (s *ControllerTestSuite) TestGeneratedName() {
+	s.Equal("deployment-web", GeneratedName("Deployment", "web"))
+
+	name := GeneratedName("StatefulSet", strings.Repeat("a", validation.DNS1123SubdomainMaxLength))
+	s.Len(name, validation.DNS1123SubdomainMaxLength)
+	s.NotEqual(name, GeneratedName("StatefulSet", strings.Repeat("a", validation.DNS1123SubdomainMaxLength-1)+"b"))
+}
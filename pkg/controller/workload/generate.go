@@ -0,0 +1,101 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"sort"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Generate returns the imagesync spec for the pod template of the workload.  The images of
+// the selected containers are synced to the nodes that the pods can be scheduled on, using
+// the node selector, the required node affinity and the tolerations of the template.  Images
+// that the injector rewrote to the coral registry or pinned to a digest are synced as the image
+// that the container originally referenced.
+func Generate(obj client.Object) coralv1beta1.ImageSyncSpec {
+	meta, template := util.PodTemplate(obj)
+	if template == nil {
+		return coralv1beta1.ImageSyncSpec{}
+	}
+
+	original := util.ParseContainerImages(meta.Annotations[coralv1beta1.ImageSyncOriginalImagesAnnotation])
+
+	annotations := obj.GetAnnotations()
+	include := util.SplitList(annotations[coralv1beta1.ImageSyncContainerIncludeAnnotation])
+	exclude := util.SplitList(annotations[coralv1beta1.ImageSyncContainerExcludeAnnotation])
+
+	spec := coralv1beta1.ImageSyncSpec{}
+
+	seen := make(map[string]bool)
+	for _, containers := range [][]corev1.Container{template.InitContainers, template.Containers} {
+		for _, container := range containers {
+			if len(include) > 0 && !include[container.Name] || exclude[container.Name] {
+				continue
+			}
+
+			image := container.Image
+			if o, ok := original[container.Name]; ok {
+				image = o
+			}
+
+			if image == "" || seen[image] {
+				continue
+			}
+
+			seen[image] = true
+			spec.Images = append(spec.Images, image)
+		}
+	}
+
+	keys := make([]string, 0, len(template.NodeSelector))
+	for k := range template.NodeSelector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		spec.NodeSelector = append(spec.NodeSelector, coralv1beta1.NodeSelector{
+			Key:      k,
+			Operator: selection.In,
+			Values:   []string{template.NodeSelector[k]},
+		})
+	}
+
+	if template.Affinity != nil && template.Affinity.NodeAffinity != nil {
+		if required := template.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			spec.NodeAffinity = &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: required.DeepCopy(),
+			}
+		}
+	}
+
+	if len(template.Tolerations) > 0 {
+		spec.Tolerations = make([]corev1.Toleration, len(template.Tolerations))
+		for i := range template.Tolerations {
+			template.Tolerations[i].DeepCopyInto(&spec.Tolerations[i])
+		}
+	}
+
+	for _, ref := range template.ImagePullSecrets {
+		spec.ImagePullSecrets = append(spec.ImagePullSecrets, corev1.LocalObjectReference{Name: ref.Name})
+	}
+
+	return spec
+}
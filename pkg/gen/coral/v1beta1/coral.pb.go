@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.5
+// 	protoc-gen-go v1.36.11-devel
 // 	protoc        (unknown)
 // source: coral/v1beta1/coral.proto
 
@@ -22,6 +22,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ImageState int32
+
+const (
+	ImageState_IMAGE_STATE_UNKNOWN   ImageState = 0
+	ImageState_IMAGE_STATE_PULLING   ImageState = 1
+	ImageState_IMAGE_STATE_FAILED    ImageState = 2
+	ImageState_IMAGE_STATE_AVAILABLE ImageState = 3
+)
+
+// Enum value maps for ImageState.
+var (
+	ImageState_name = map[int32]string{
+		0: "IMAGE_STATE_UNKNOWN",
+		1: "IMAGE_STATE_PULLING",
+		2: "IMAGE_STATE_FAILED",
+		3: "IMAGE_STATE_AVAILABLE",
+	}
+	ImageState_value = map[string]int32{
+		"IMAGE_STATE_UNKNOWN":   0,
+		"IMAGE_STATE_PULLING":   1,
+		"IMAGE_STATE_FAILED":    2,
+		"IMAGE_STATE_AVAILABLE": 3,
+	}
+)
+
+func (x ImageState) Enum() *ImageState {
+	p := new(ImageState)
+	*p = x
+	return p
+}
+
+func (x ImageState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageState) Descriptor() protoreflect.EnumDescriptor {
+	return file_coral_v1beta1_coral_proto_enumTypes[0].Descriptor()
+}
+
+func (ImageState) Type() protoreflect.EnumType {
+	return &file_coral_v1beta1_coral_proto_enumTypes[0]
+}
+
+func (x ImageState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageState.Descriptor instead.
+func (ImageState) EnumDescriptor() ([]byte, []int) {
+	return file_coral_v1beta1_coral_proto_rawDescGZIP(), []int{0}
+}
+
 type ReporterStatus int32
 
 const (
@@ -58,11 +110,11 @@ func (x ReporterStatus) String() string {
 }
 
 func (ReporterStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_coral_v1beta1_coral_proto_enumTypes[0].Descriptor()
+	return file_coral_v1beta1_coral_proto_enumTypes[1].Descriptor()
 }
 
 func (ReporterStatus) Type() protoreflect.EnumType {
-	return &file_coral_v1beta1_coral_proto_enumTypes[0]
+	return &file_coral_v1beta1_coral_proto_enumTypes[1]
 }
 
 func (x ReporterStatus) Number() protoreflect.EnumNumber {
@@ -71,13 +123,16 @@ func (x ReporterStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ReporterStatus.Descriptor instead.
 func (ReporterStatus) EnumDescriptor() ([]byte, []int) {
-	return file_coral_v1beta1_coral_proto_rawDescGZIP(), []int{0}
+	return file_coral_v1beta1_coral_proto_rawDescGZIP(), []int{1}
 }
 
 type ReporterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Image         []string               `protobuf:"bytes,1,rep,name=image,proto3" json:"image,omitempty"`
 	Node          string                 `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+	NodeLabels    string                 `protobuf:"bytes,3,opt,name=node_labels,json=nodeLabels,proto3" json:"node_labels,omitempty"`
+	Pinned        []string               `protobuf:"bytes,4,rep,name=pinned,proto3" json:"pinned,omitempty"`
+	Images        []*ImageStatus         `protobuf:"bytes,5,rep,name=images,proto3" json:"images,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -126,6 +181,103 @@ func (x *ReporterRequest) GetNode() string {
 	return ""
 }
 
+func (x *ReporterRequest) GetNodeLabels() string {
+	if x != nil {
+		return x.NodeLabels
+	}
+	return ""
+}
+
+func (x *ReporterRequest) GetPinned() []string {
+	if x != nil {
+		return x.Pinned
+	}
+	return nil
+}
+
+func (x *ReporterRequest) GetImages() []*ImageStatus {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+type ImageStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Image         string                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	State         ImageState             `protobuf:"varint,2,opt,name=state,proto3,enum=coral.v1beta1.ImageState" json:"state,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Digest        string                 `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
+	Size          uint64                 `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageStatus) Reset() {
+	*x = ImageStatus{}
+	mi := &file_coral_v1beta1_coral_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageStatus) ProtoMessage() {}
+
+func (x *ImageStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_coral_v1beta1_coral_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageStatus.ProtoReflect.Descriptor instead.
+func (*ImageStatus) Descriptor() ([]byte, []int) {
+	return file_coral_v1beta1_coral_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ImageStatus) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ImageStatus) GetState() ImageState {
+	if x != nil {
+		return x.State
+	}
+	return ImageState_IMAGE_STATE_UNKNOWN
+}
+
+func (x *ImageStatus) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ImageStatus) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *ImageStatus) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 type ReporterResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
@@ -136,7 +288,7 @@ type ReporterResponse struct {
 
 func (x *ReporterResponse) Reset() {
 	*x = ReporterResponse{}
-	mi := &file_coral_v1beta1_coral_proto_msgTypes[1]
+	mi := &file_coral_v1beta1_coral_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -148,7 +300,7 @@ func (x *ReporterResponse) String() string {
 func (*ReporterResponse) ProtoMessage() {}
 
 func (x *ReporterResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_coral_v1beta1_coral_proto_msgTypes[1]
+	mi := &file_coral_v1beta1_coral_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -161,7 +313,7 @@ func (x *ReporterResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReporterResponse.ProtoReflect.Descriptor instead.
 func (*ReporterResponse) Descriptor() ([]byte, []int) {
-	return file_coral_v1beta1_coral_proto_rawDescGZIP(), []int{1}
+	return file_coral_v1beta1_coral_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *ReporterResponse) GetMessage() string {
@@ -180,43 +332,40 @@ func (x *ReporterResponse) GetStatus() ReporterStatus {
 
 var File_coral_v1beta1_coral_proto protoreflect.FileDescriptor
 
-var file_coral_v1beta1_coral_proto_rawDesc = string([]byte{
-	0x0a, 0x19, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x2f,
-	0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x63, 0x6f, 0x72,
-	0x61, 0x6c, 0x2e, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x22, 0x3b, 0x0a, 0x0f, 0x52, 0x65,
-	0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
-	0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x69, 0x6d,
-	0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x22, 0x63, 0x0a, 0x10, 0x52, 0x65, 0x70, 0x6f, 0x72,
-	0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x35, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2e, 0x76, 0x31,
-	0x62, 0x65, 0x74, 0x61, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2a, 0x4b, 0x0a, 0x0e,
-	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b,
-	0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x06, 0x0a, 0x02, 0x4f,
-	0x4b, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x52, 0x45, 0x54, 0x52, 0x59, 0x41, 0x42, 0x4c, 0x45,
-	0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x46, 0x41, 0x54, 0x41,
-	0x4c, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x03, 0x32, 0x5d, 0x0a, 0x0c, 0x43, 0x6f, 0x72,
-	0x61, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4d, 0x0a, 0x08, 0x52, 0x65, 0x70,
-	0x6f, 0x72, 0x74, 0x65, 0x72, 0x12, 0x1e, 0x2e, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2e, 0x76, 0x31,
-	0x62, 0x65, 0x74, 0x61, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2e, 0x76, 0x31,
-	0x62, 0x65, 0x74, 0x61, 0x31, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x72, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0xa5, 0x01, 0x0a, 0x11, 0x63, 0x6f, 0x6d,
-	0x2e, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2e, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0x42, 0x0a,
-	0x43, 0x6f, 0x72, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x2f, 0x63, 0x74,
-	0x78, 0x2e, 0x73, 0x68, 0x2f, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x67,
-	0x65, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x2f, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31,
-	0x3b, 0x63, 0x6f, 0x72, 0x61, 0x6c, 0x76, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31, 0xa2, 0x02, 0x03,
-	0x43, 0x58, 0x58, 0xaa, 0x02, 0x0d, 0x43, 0x6f, 0x72, 0x61, 0x6c, 0x2e, 0x56, 0x31, 0x62, 0x65,
-	0x74, 0x61, 0x31, 0xca, 0x02, 0x0d, 0x43, 0x6f, 0x72, 0x61, 0x6c, 0x5c, 0x56, 0x31, 0x62, 0x65,
-	0x74, 0x61, 0x31, 0xe2, 0x02, 0x19, 0x43, 0x6f, 0x72, 0x61, 0x6c, 0x5c, 0x56, 0x31, 0x62, 0x65,
-	0x74, 0x61, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea,
-	0x02, 0x0e, 0x43, 0x6f, 0x72, 0x61, 0x6c, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x65, 0x74, 0x61, 0x31,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-})
+const file_coral_v1beta1_coral_proto_rawDesc = "" +
+	"\n" +
+	"\x19coral/v1beta1/coral.proto\x12\rcoral.v1beta1\"\xa8\x01\n" +
+	"\x0fReporterRequest\x12\x14\n" +
+	"\x05image\x18\x01 \x03(\tR\x05image\x12\x12\n" +
+	"\x04node\x18\x02 \x01(\tR\x04node\x12\x1f\n" +
+	"\vnode_labels\x18\x03 \x01(\tR\n" +
+	"nodeLabels\x12\x16\n" +
+	"\x06pinned\x18\x04 \x03(\tR\x06pinned\x122\n" +
+	"\x06images\x18\x05 \x03(\v2\x1a.coral.v1beta1.ImageStatusR\x06images\"\x98\x01\n" +
+	"\vImageStatus\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\tR\x05image\x12/\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x19.coral.v1beta1.ImageStateR\x05state\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06digest\x18\x04 \x01(\tR\x06digest\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x04R\x04size\"c\n" +
+	"\x10ReporterResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x125\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1d.coral.v1beta1.ReporterStatusR\x06status*q\n" +
+	"\n" +
+	"ImageState\x12\x17\n" +
+	"\x13IMAGE_STATE_UNKNOWN\x10\x00\x12\x17\n" +
+	"\x13IMAGE_STATE_PULLING\x10\x01\x12\x16\n" +
+	"\x12IMAGE_STATE_FAILED\x10\x02\x12\x19\n" +
+	"\x15IMAGE_STATE_AVAILABLE\x10\x03*K\n" +
+	"\x0eReporterStatus\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\x06\n" +
+	"\x02OK\x10\x01\x12\x13\n" +
+	"\x0fRETRYABLE_ERROR\x10\x02\x12\x0f\n" +
+	"\vFATAL_ERROR\x10\x032]\n" +
+	"\fCoralService\x12M\n" +
+	"\bReporter\x12\x1e.coral.v1beta1.ReporterRequest\x1a\x1f.coral.v1beta1.ReporterResponse\"\x00B\xa5\x01\n" +
+	"\x11com.coral.v1beta1B\n" +
+	"CoralProtoP\x01Z/ctx.sh/coral/pkg/gen/coral/v1beta1;coralv1beta1\xa2\x02\x03CXX\xaa\x02\rCoral.V1beta1\xca\x02\rCoral\\V1beta1\xe2\x02\x19Coral\\V1beta1\\GPBMetadata\xea\x02\x0eCoral::V1beta1b\x06proto3"
 
 var (
 	file_coral_v1beta1_coral_proto_rawDescOnce sync.Once
@@ -230,22 +379,26 @@ func file_coral_v1beta1_coral_proto_rawDescGZIP() []byte {
 	return file_coral_v1beta1_coral_proto_rawDescData
 }
 
-var file_coral_v1beta1_coral_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_coral_v1beta1_coral_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_coral_v1beta1_coral_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_coral_v1beta1_coral_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_coral_v1beta1_coral_proto_goTypes = []any{
-	(ReporterStatus)(0),      // 0: coral.v1beta1.ReporterStatus
-	(*ReporterRequest)(nil),  // 1: coral.v1beta1.ReporterRequest
-	(*ReporterResponse)(nil), // 2: coral.v1beta1.ReporterResponse
+	(ImageState)(0),          // 0: coral.v1beta1.ImageState
+	(ReporterStatus)(0),      // 1: coral.v1beta1.ReporterStatus
+	(*ReporterRequest)(nil),  // 2: coral.v1beta1.ReporterRequest
+	(*ImageStatus)(nil),      // 3: coral.v1beta1.ImageStatus
+	(*ReporterResponse)(nil), // 4: coral.v1beta1.ReporterResponse
 }
 var file_coral_v1beta1_coral_proto_depIdxs = []int32{
-	0, // 0: coral.v1beta1.ReporterResponse.status:type_name -> coral.v1beta1.ReporterStatus
-	1, // 1: coral.v1beta1.CoralService.Reporter:input_type -> coral.v1beta1.ReporterRequest
-	2, // 2: coral.v1beta1.CoralService.Reporter:output_type -> coral.v1beta1.ReporterResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 0: coral.v1beta1.ReporterRequest.images:type_name -> coral.v1beta1.ImageStatus
+	0, // 1: coral.v1beta1.ImageStatus.state:type_name -> coral.v1beta1.ImageState
+	1, // 2: coral.v1beta1.ReporterResponse.status:type_name -> coral.v1beta1.ReporterStatus
+	2, // 3: coral.v1beta1.CoralService.Reporter:input_type -> coral.v1beta1.ReporterRequest
+	4, // 4: coral.v1beta1.CoralService.Reporter:output_type -> coral.v1beta1.ReporterResponse
+	4, // [4:5] is the sub-list for method output_type
+	3, // [3:4] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_coral_v1beta1_coral_proto_init() }
@@ -258,8 +411,8 @@ func file_coral_v1beta1_coral_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_coral_v1beta1_coral_proto_rawDesc), len(file_coral_v1beta1_coral_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   2,
+			NumEnums:      2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
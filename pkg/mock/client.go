@@ -48,7 +48,7 @@ func NewClient() *Client {
 	c := fake.NewClientBuilder().
 		WithObjectTracker(tracker).
 		WithScheme(s).
-		WithStatusSubresource(&coralv1beta1.ImageSync{}).
+		WithStatusSubresource(&coralv1beta1.ImageSync{}, &coralv1beta1.ClusterImageSync{}, &coralv1beta1.Mirror{}).
 		Build()
 
 	return &Client{
@@ -152,6 +152,109 @@ func (_c *MockImageClient_List_Call) RunAndReturn(run func(ctx context.Context)
 	return _c
 }
 
+// Pin provides a mock function for the type MockImageClient
+func (_mock *MockImageClient) Pin(ctx context.Context, owner string, images []string) error {
+	ret := _mock.Called(ctx, owner, images)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pin")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = returnFunc(ctx, owner, images)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockImageClient_Pin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pin'
+type MockImageClient_Pin_Call struct {
+	*mock.Call
+}
+
+// Pin is a helper method to define mock.On call
+//   - ctx
+//   - owner
+//   - images
+func (_e *MockImageClient_Expecter) Pin(ctx interface{}, owner interface{}, images interface{}) *MockImageClient_Pin_Call {
+	return &MockImageClient_Pin_Call{Call: _e.mock.On("Pin", ctx, owner, images)}
+}
+
+func (_c *MockImageClient_Pin_Call) Run(run func(ctx context.Context, owner string, images []string)) *MockImageClient_Pin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockImageClient_Pin_Call) Return(err error) *MockImageClient_Pin_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockImageClient_Pin_Call) RunAndReturn(run func(ctx context.Context, owner string, images []string) error) *MockImageClient_Pin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Pinned provides a mock function for the type MockImageClient
+func (_mock *MockImageClient) Pinned(ctx context.Context) ([]string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pinned")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockImageClient_Pinned_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pinned'
+type MockImageClient_Pinned_Call struct {
+	*mock.Call
+}
+
+// Pinned is a helper method to define mock.On call
+//   - ctx
+func (_e *MockImageClient_Expecter) Pinned(ctx interface{}) *MockImageClient_Pinned_Call {
+	return &MockImageClient_Pinned_Call{Call: _e.mock.On("Pinned", ctx)}
+}
+
+func (_c *MockImageClient_Pinned_Call) Run(run func(ctx context.Context)) *MockImageClient_Pinned_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockImageClient_Pinned_Call) Return(strings []string, err error) *MockImageClient_Pinned_Call {
+	_c.Call.Return(strings, err)
+	return _c
+}
+
+func (_c *MockImageClient_Pinned_Call) RunAndReturn(run func(ctx context.Context) ([]string, error)) *MockImageClient_Pinned_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Pull provides a mock function for the type MockImageClient
 func (_mock *MockImageClient) Pull(ctx context.Context, name string, auth []*v1.AuthConfig) error {
 	ret := _mock.Called(ctx, name, auth)
@@ -254,6 +357,52 @@ func (_c *MockImageClient_Status_Call) RunAndReturn(run func(ctx context.Context
 	return _c
 }
 
+// Unpin provides a mock function for the type MockImageClient
+func (_mock *MockImageClient) Unpin(ctx context.Context, owner string) error {
+	ret := _mock.Called(ctx, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unpin")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, owner)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockImageClient_Unpin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unpin'
+type MockImageClient_Unpin_Call struct {
+	*mock.Call
+}
+
+// Unpin is a helper method to define mock.On call
+//   - ctx
+//   - owner
+func (_e *MockImageClient_Expecter) Unpin(ctx interface{}, owner interface{}) *MockImageClient_Unpin_Call {
+	return &MockImageClient_Unpin_Call{Call: _e.mock.On("Unpin", ctx, owner)}
+}
+
+func (_c *MockImageClient_Unpin_Call) Run(run func(ctx context.Context, owner string)) *MockImageClient_Unpin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockImageClient_Unpin_Call) Return(err error) *MockImageClient_Unpin_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockImageClient_Unpin_Call) RunAndReturn(run func(ctx context.Context, owner string) error) *MockImageClient_Unpin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockImageServiceClient creates a new instance of MockImageServiceClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockImageServiceClient(t interface {
@@ -0,0 +1,45 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import "sync"
+
+// Digests holds the most recently resolved digest for each fully qualified image.
+type Digests struct {
+	digests map[string]string
+	sync.Mutex
+}
+
+func NewDigests() *Digests {
+	return &Digests{
+		digests: make(map[string]string),
+	}
+}
+
+// Set records the digest for an image.
+func (d *Digests) Set(image, digest string) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.digests[image] = digest
+}
+
+// Get returns the digest for an image or an empty string if it has not been resolved.
+func (d *Digests) Get(image string) string {
+	d.Lock()
+	defer d.Unlock()
+
+	return d.digests[image]
+}
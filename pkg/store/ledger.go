@@ -0,0 +1,117 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"sort"
+	"sync"
+)
+
+// Ledger records which owners (imagesync uids) have ensured which fully qualified images
+// on a node.  Images are reference counted so that an image shared by multiple owners is
+// only released once the last of them has let it go.
+type Ledger struct {
+	owners map[string]map[string]bool
+	images *Store[string]
+
+	sync.Mutex
+}
+
+func NewLedger() *Ledger {
+	return &Ledger{
+		owners: make(map[string]map[string]bool),
+		images: New[string](),
+	}
+}
+
+// Set replaces the images held by the owner.  Any images that were previously held by the
+// owner, are no longer in the list, and are no longer referenced by any other owner are
+// returned.
+func (l *Ledger) Set(owner string, images []string) []string {
+	l.Lock()
+	defer l.Unlock()
+
+	current := make(map[string]bool, len(images))
+	for _, image := range images {
+		current[image] = true
+	}
+
+	previous := l.owners[owner]
+	for image := range current {
+		if !previous[image] {
+			l.images.Add(image)
+		}
+	}
+
+	released := make([]string, 0)
+	for image := range previous {
+		if !current[image] {
+			released = append(released, l.release(image)...)
+		}
+	}
+
+	l.owners[owner] = current
+	return released
+}
+
+// Remove drops all images held by the owner and returns the ones that are no longer
+// referenced by any other owner.
+func (l *Ledger) Remove(owner string) []string {
+	l.Lock()
+	defer l.Unlock()
+
+	released := make([]string, 0)
+	for image := range l.owners[owner] {
+		released = append(released, l.release(image)...)
+	}
+
+	delete(l.owners, owner)
+	return released
+}
+
+// IsReferenced returns true if any owner holds the image.
+func (l *Ledger) IsReferenced(image string) bool {
+	return l.images.IsReferenced(image)
+}
+
+// Images returns a sorted list of all images held by at least one owner.
+func (l *Ledger) Images() []string {
+	l.Lock()
+	defer l.Unlock()
+
+	seen := make(map[string]bool)
+	for _, images := range l.owners {
+		for image := range images {
+			seen[image] = true
+		}
+	}
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+
+	sort.Strings(images)
+	return images
+}
+
+func (l *Ledger) release(image string) []string {
+	l.images.Delete(image)
+	if l.images.IsReferenced(image) {
+		return nil
+	}
+
+	return []string{image}
+}
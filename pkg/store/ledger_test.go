@@ -0,0 +1,66 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LedgerTestSuite struct {
+	suite.Suite
+}
+
+func TestLedgerTestSuite(t *testing.T) {
+	suite.Run(t, new(LedgerTestSuite))
+}
+
+func (s *LedgerTestSuite) TestSet() {
+	l := NewLedger()
+
+	released := l.Set("a", []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+	s.Empty(released)
+	s.True(l.IsReferenced("docker.io/library/golang:latest"))
+	s.True(l.IsReferenced("docker.io/library/nginx:latest"))
+
+	// Setting the same images again should not increase the reference counts.
+	released = l.Set("a", []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+	s.Empty(released)
+	s.Equal(1, l.images.References("docker.io/library/golang:latest"))
+
+	released = l.Set("a", []string{"docker.io/library/golang:latest"})
+	s.Equal([]string{"docker.io/library/nginx:latest"}, released)
+	s.False(l.IsReferenced("docker.io/library/nginx:latest"))
+	s.Equal([]string{"docker.io/library/golang:latest"}, l.Images())
+}
+
+func (s *LedgerTestSuite) TestShared() {
+	l := NewLedger()
+
+	l.Set("a", []string{"docker.io/library/golang:latest", "docker.io/library/nginx:latest"})
+	l.Set("b", []string{"docker.io/library/nginx:latest"})
+
+	released := l.Remove("a")
+	s.Equal([]string{"docker.io/library/golang:latest"}, released)
+	s.True(l.IsReferenced("docker.io/library/nginx:latest"))
+
+	released = l.Set("b", []string{})
+	s.Equal([]string{"docker.io/library/nginx:latest"}, released)
+	s.Empty(l.Images())
+
+	// Removing an unknown owner is a no-op.
+	s.Empty(l.Remove("c"))
+}
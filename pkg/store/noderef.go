@@ -18,6 +18,8 @@ import "sync"
 
 type Images struct {
 	images map[string]bool
+	pinned map[string]bool
+	failed map[string]string
 }
 
 type NodeRef struct {
@@ -49,6 +51,61 @@ func (nr *NodeRef) addImage(nodeName, imageName string) {
 	nr.refs[nodeName].images[imageName] = true
 }
 
+// SetPinned replaces the list of images that are pinned on a node.
+func (nr *NodeRef) SetPinned(nodeName string, images []string) {
+	nr.Lock()
+	defer nr.Unlock()
+
+	if _, exists := nr.refs[nodeName]; !exists {
+		nr.refs[nodeName] = &Images{images: make(map[string]bool)}
+	}
+
+	pinned := make(map[string]bool, len(images))
+	for _, image := range images {
+		pinned[image] = true
+	}
+	nr.refs[nodeName].pinned = pinned
+}
+
+// IsPinned checks if a node has a specific image pinned.
+func (nr *NodeRef) IsPinned(nodeName, imageName string) bool {
+	nr.Lock()
+	defer nr.Unlock()
+
+	if images, exists := nr.refs[nodeName]; exists {
+		return images.pinned[imageName]
+	}
+
+	return false
+}
+
+// SetFailed replaces the images that failed to pull on a node along with the reason that
+// the pull failed.
+func (nr *NodeRef) SetFailed(nodeName string, failed map[string]string) {
+	nr.Lock()
+	defer nr.Unlock()
+
+	if _, exists := nr.refs[nodeName]; !exists {
+		nr.refs[nodeName] = &Images{images: make(map[string]bool)}
+	}
+
+	nr.refs[nodeName].failed = failed
+}
+
+// Failure returns the reason that an image failed to pull on a node and whether the
+// pull failed.
+func (nr *NodeRef) Failure(nodeName, imageName string) (string, bool) {
+	nr.Lock()
+	defer nr.Unlock()
+
+	if images, exists := nr.refs[nodeName]; exists {
+		reason, failed := images.failed[imageName]
+		return reason, failed
+	}
+
+	return "", false
+}
+
 // HasImage checks if a node has a specific image.
 func (nr *NodeRef) HasImage(nodeName, imageName string) bool {
 	nr.Lock()
@@ -63,6 +120,20 @@ func (nr *NodeRef) HasImage(nodeName, imageName string) bool {
 	return false
 }
 
+// IsAvailable returns true if the image is available on any of the nodes.
+func (nr *NodeRef) IsAvailable(imageName string) bool {
+	nr.Lock()
+	defer nr.Unlock()
+
+	for _, images := range nr.refs {
+		if images.images[imageName] {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetNodes returns a list of node names that are being tracked.
 func (nr *NodeRef) GetNodes() []string {
 	nr.Lock()
@@ -0,0 +1,74 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import "sync"
+
+// Pull is the state of the most recent pull of an image on the node.
+type Pull struct {
+	// Failed is true if the most recent pull of the image failed.
+	Failed bool
+	// Reason is the reason that the pull failed.
+	Reason string
+}
+
+// Pulls tracks images that are being pulled or that failed to pull on the node.  Images that
+// have been pulled successfully are not tracked.
+type Pulls struct {
+	pulls map[string]Pull
+	sync.Mutex
+}
+
+func NewPulls() *Pulls {
+	return &Pulls{
+		pulls: make(map[string]Pull),
+	}
+}
+
+// Start records that the image is being pulled.  Any previous failure is kept until the
+// pull completes so a failing image does not flap between pulling and failed.
+func (p *Pulls) Start(image string) {
+	p.Lock()
+	defer p.Unlock()
+
+	if _, ok := p.pulls[image]; !ok {
+		p.pulls[image] = Pull{}
+	}
+}
+
+// Fail records that the pull of the image failed.
+func (p *Pulls) Fail(image, reason string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.pulls[image] = Pull{Failed: true, Reason: reason}
+}
+
+// Done removes the image once it has been pulled or is no longer managed.
+func (p *Pulls) Done(image string) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.pulls, image)
+}
+
+// Get returns the pull state of the image and whether it is being tracked.
+func (p *Pulls) Get(image string) (Pull, bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	pull, ok := p.pulls[image]
+	return pull, ok
+}
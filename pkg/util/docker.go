@@ -18,6 +18,8 @@ import (
 	"crypto/md5" // #nosec G501
 	"encoding/hex"
 	"strings"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
 )
 
 const (
@@ -25,6 +27,10 @@ const (
 	DefaultSearchRegistry = "docker.io"
 )
 
+// GetImageQualifiedName returns the image with the registry, library namespace and latest tag
+// filled in.
+//
+// Deprecated: Use ParseReference and Reference.String instead.
 func GetImageQualifiedName(search, image string) string {
 	name := image
 
@@ -46,6 +52,10 @@ func GetImageQualifiedName(search, image string) string {
 	return name
 }
 
+// ExtractImageHostname returns the first component of the image if it looks like a registry
+// hostname, otherwise the default search registry.
+//
+// Deprecated: Use ParseReference and Reference.Domain instead.
 func ExtractImageHostname(image string) string {
 	parts := strings.Split(image, "/")
 	if len(parts) == 1 {
@@ -61,6 +71,10 @@ func ExtractImageHostname(image string) string {
 	return DefaultSearchRegistry
 }
 
+// ExtractImageName returns the image without its first component, with the latest tag added
+// when the remainder has no tag.
+//
+// Deprecated: Use ParseReference and Reference.Name instead.
 func ExtractImageName(image string) string {
 	parts := strings.SplitN(image, "/", 2)
 	if len(parts) == 1 {
@@ -80,3 +94,24 @@ func GetImageLabelValue(image string) string {
 	md5Hash := md5.Sum([]byte(image)) // #nosec G401
 	return hex.EncodeToString(md5Hash[:])
 }
+
+// GetImageLabelKey returns the node label key that marks an image as available on the node.
+// The image is normalized first so that all forms of a reference map to the same key.
+func GetImageLabelKey(image string) string {
+	if ref, err := ParseReference(image); err == nil {
+		image = ref.String()
+	}
+
+	return coralv1beta1.ImageSyncLabel + "/" + GetImageLabelValue(image)
+}
+
+// IsImageLabelKey returns true if the node label key is an image availability label.
+func IsImageLabelKey(key string) bool {
+	name, found := strings.CutPrefix(key, coralv1beta1.ImageSyncLabel+"/")
+	if !found || len(name) != hex.EncodedLen(md5.Size) {
+		return false
+	}
+
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
@@ -357,3 +357,12 @@ func TestExtractImageHostname(t *testing.T) {
 		})
 	}
 }
+
+func TestGetImageLabelKey(t *testing.T) {
+	key := GetImageLabelKey("ubuntu")
+	assert.Equal(t, "imagesync.coral.ctx.sh/942849ee1519b80488bad583231163a5", key)
+	assert.Equal(t, key, GetImageLabelKey("docker.io/library/ubuntu:latest"))
+	assert.True(t, IsImageLabelKey(key))
+	assert.False(t, IsImageLabelKey("imagesync.coral.ctx.sh/enabled"))
+	assert.False(t, IsImageLabelKey("example.com/942849ee1519b80488bad583231163a5"))
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListImageSyncs returns all of the imagesyncs and clusterimagesyncs.
+func ListImageSyncs(ctx context.Context, c client.Reader, opts ...client.ListOption) ([]coralv1beta1.ImageSyncObject, error) {
+	var isyncs coralv1beta1.ImageSyncList
+	if err := c.List(ctx, &isyncs, opts...); err != nil {
+		return nil, err
+	}
+
+	var cisyncs coralv1beta1.ClusterImageSyncList
+	if err := c.List(ctx, &cisyncs, opts...); err != nil {
+		return nil, err
+	}
+
+	return append(isyncs.Objects(), cisyncs.Objects()...), nil
+}
+
+// ListImageSyncsInScope returns the imagesyncs in the namespace along with the
+// clusterimagesyncs, skipping any that are being deleted.
+func ListImageSyncsInScope(ctx context.Context, c client.Reader, namespace string) ([]coralv1beta1.ImageSyncObject, error) {
+	items, err := ListImageSyncs(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	isyncs := make([]coralv1beta1.ImageSyncObject, 0, len(items))
+	for _, isync := range items {
+		if isync.GetNamespace() != "" && isync.GetNamespace() != namespace {
+			continue
+		}
+
+		if isync.GetDeletionTimestamp().IsZero() {
+			isyncs = append(isyncs, isync)
+		}
+	}
+
+	return isyncs, nil
+}
+
+// NewImageSyncObject returns an empty imagesync object, or clusterimagesync object if cluster
+// is true, that can be used to get the resource from the api server.
+func NewImageSyncObject(cluster bool) coralv1beta1.ImageSyncObject {
+	if cluster {
+		return &coralv1beta1.ClusterImageSync{}
+	}
+
+	return &coralv1beta1.ImageSync{}
+}
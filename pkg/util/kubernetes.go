@@ -16,13 +16,30 @@ package util
 
 import (
 	"fmt"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// WorkloadKinds returns the group kinds of the workloads that carry a pod template.
+func WorkloadKinds() []schema.GroupKind {
+	return []schema.GroupKind{
+		batchv1.SchemeGroupVersion.WithKind("CronJob").GroupKind(),
+		appsv1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind(),
+		appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind(),
+		batchv1.SchemeGroupVersion.WithKind("Job").GroupKind(),
+		appsv1.SchemeGroupVersion.WithKind("ReplicaSet").GroupKind(),
+		corev1.SchemeGroupVersion.WithKind("ReplicationController").GroupKind(),
+		appsv1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind(),
+		corev1.SchemeGroupVersion.WithKind("Pod").GroupKind(),
+	}
+}
+
 func GroupKindToType(gk schema.GroupKind) (any, error) {
 	switch gk {
 	case batchv1.SchemeGroupVersion.WithKind("CronJob").GroupKind():
@@ -45,3 +62,57 @@ func GroupKindToType(gk schema.GroupKind) (any, error) {
 		return nil, fmt.Errorf("unknown group kind %v", gk)
 	}
 }
+
+// PodTemplate returns the metadata and spec of the pods that are created from the object.
+// For pods the object's own metadata and spec are returned.  Nil is returned if the object
+// does not carry a pod template.
+func PodTemplate(obj client.Object) (*metav1.ObjectMeta, *corev1.PodSpec) {
+	switch o := obj.(type) {
+	case *batchv1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template.ObjectMeta, &o.Spec.JobTemplate.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec
+	case *appsv1.Deployment:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec
+	case *batchv1.Job:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec
+	case *corev1.ReplicationController:
+		if o.Spec.Template == nil {
+			return nil, nil
+		}
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec
+	case *corev1.Pod:
+		return &o.ObjectMeta, &o.Spec
+	default:
+		return nil, nil
+	}
+}
+
+// ParseContainerImages returns the images of a comma separated annotation value in the form
+// <container>=<image> keyed by the container name.
+func ParseContainerImages(value string) map[string]string {
+	images := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		if name, image, ok := strings.Cut(strings.TrimSpace(entry), "="); ok && name != "" {
+			images[name] = image
+		}
+	}
+
+	return images
+}
+
+// SplitList returns the non-empty, trimmed items of a comma separated annotation value.
+func SplitList(value string) map[string]bool {
+	items := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items[item] = true
+		}
+	}
+
+	return items
+}
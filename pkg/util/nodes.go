@@ -0,0 +1,219 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// NodeMatcher evaluates the node selector, the required node affinity and the tolerations
+// of an imagesync against nodes.  It is shared by the agents and the controller so that
+// both agree on which nodes an imagesync targets.
+type NodeMatcher struct {
+	selector    labels.Selector
+	terms       []nodeSelectorTerm
+	affinity    bool
+	tolerations []corev1.Toleration
+	taints      bool
+	nodeName    string
+}
+
+type nodeSelectorTerm struct {
+	labels labels.Selector
+	fields labels.Selector
+}
+
+// NewNodeMatcher returns a matcher for the imagesync.  An error is returned if any of the
+// selectors are invalid.
+func NewNodeMatcher(obj coralv1beta1.ImageSyncObject) (*NodeMatcher, error) {
+	selector := labels.NewSelector()
+	for _, v := range obj.GetNodeSelector() {
+		req, err := labels.NewRequirement(v.Key, v.Operator, v.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*req)
+	}
+
+	// Taints are only considered when tolerations are given to stay compatible with the
+	// imagesyncs that were created before tolerations were supported.
+	tolerations := obj.GetTolerations()
+
+	return newNodeMatcher(selector, obj.GetNodeAffinity(), tolerations, len(tolerations) > 0)
+}
+
+// NewPodNodeMatcher returns a matcher for the nodes that a pod with the spec could be
+// scheduled on.  Unlike the imagesync matcher, the taints are always considered.
+func NewPodNodeMatcher(spec *corev1.PodSpec) (*NodeMatcher, error) {
+	selector := labels.SelectorFromSet(spec.NodeSelector)
+
+	var affinity *corev1.NodeAffinity
+	if spec.Affinity != nil {
+		affinity = spec.Affinity.NodeAffinity
+	}
+
+	m, err := newNodeMatcher(selector, affinity, spec.Tolerations, true)
+	if err != nil {
+		return nil, err
+	}
+
+	m.nodeName = spec.NodeName
+
+	return m, nil
+}
+
+func newNodeMatcher(selector labels.Selector, affinity *corev1.NodeAffinity, tolerations []corev1.Toleration, taints bool) (*NodeMatcher, error) {
+	m := &NodeMatcher{
+		selector:    selector,
+		tolerations: tolerations,
+		taints:      taints,
+	}
+
+	if affinity == nil || affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return m, nil
+	}
+
+	m.affinity = true
+	for _, term := range affinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		// Empty terms match no nodes.
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		ls, err := requirements(term.MatchExpressions)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range term.MatchFields {
+			if v.Key != "metadata.name" {
+				return nil, fmt.Errorf("unsupported field selector key %q", v.Key)
+			}
+		}
+
+		fs, err := requirements(term.MatchFields)
+		if err != nil {
+			return nil, err
+		}
+
+		m.terms = append(m.terms, nodeSelectorTerm{labels: ls, fields: fs})
+	}
+
+	return m, nil
+}
+
+// Matches returns true if the node is selected by the node selector and one of the required
+// node affinity terms and, when taints are considered, tolerates all of the NoSchedule and
+// NoExecute taints on the node.
+func (m *NodeMatcher) Matches(node *corev1.Node) bool {
+	if m.nodeName != "" && m.nodeName != node.GetName() {
+		return false
+	}
+
+	if !m.selector.Matches(labels.Set(node.GetLabels())) {
+		return false
+	}
+
+	if m.affinity && !m.matchesTerms(node) {
+		return false
+	}
+
+	if m.taints && !m.tolerates(node.Spec.Taints) {
+		return false
+	}
+
+	return true
+}
+
+func (m *NodeMatcher) matchesTerms(node *corev1.Node) bool {
+	nodeLabels := labels.Set(node.GetLabels())
+	nodeFields := labels.Set{"metadata.name": node.GetName()}
+
+	for _, term := range m.terms {
+		if term.labels.Matches(nodeLabels) && term.fields.Matches(nodeFields) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *NodeMatcher) tolerates(taints []corev1.Taint) bool {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for j := range m.tolerations {
+			// The alpha comparison operators are not supported.
+			if m.tolerations[j].ToleratesTaint(logr.Discard(), taint, false) {
+				tolerated = true
+				break
+			}
+		}
+
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NodeMatches is a convenience wrapper that builds a matcher for the imagesync and
+// evaluates it against a single node.
+func NodeMatches(node *corev1.Node, obj coralv1beta1.ImageSyncObject) (bool, error) {
+	m, err := NewNodeMatcher(obj)
+	if err != nil {
+		return false, err
+	}
+
+	return m.Matches(node), nil
+}
+
+func requirements(exprs []corev1.NodeSelectorRequirement) (labels.Selector, error) {
+	selector := labels.NewSelector()
+	for _, expr := range exprs {
+		op, ok := operators[expr.Operator]
+		if !ok {
+			return nil, fmt.Errorf("unsupported node selector operator %q", expr.Operator)
+		}
+
+		req, err := labels.NewRequirement(expr.Key, op, expr.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*req)
+	}
+
+	return selector, nil
+}
+
+var operators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
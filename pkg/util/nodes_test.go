@@ -0,0 +1,301 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func testNode(name string, labels map[string]string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{Taints: taints},
+	}
+}
+
+func requiredAffinity(terms ...corev1.NodeSelectorTerm) *corev1.NodeAffinity {
+	return &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: terms,
+		},
+	}
+}
+
+func TestNodeMatcher(t *testing.T) {
+	gpuTaint := corev1.Taint{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	softTaint := corev1.Taint{Key: "soft", Effect: corev1.TaintEffectPreferNoSchedule}
+
+	tests := []struct {
+		name     string
+		spec     coralv1beta1.ImageSyncSpec
+		node     *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "no selectors match all nodes",
+			node:     testNode("node1", map[string]string{"env": "prod"}),
+			expected: true,
+		},
+		{
+			name: "node selector matches",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeSelector: []coralv1beta1.NodeSelector{
+					{Key: "env", Operator: selection.In, Values: []string{"prod"}},
+				},
+			},
+			node:     testNode("node1", map[string]string{"env": "prod"}),
+			expected: true,
+		},
+		{
+			name: "node selector does not match",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeSelector: []coralv1beta1.NodeSelector{
+					{Key: "env", Operator: selection.NotIn, Values: []string{"prod"}},
+				},
+			},
+			node:     testNode("node1", map[string]string{"env": "prod"}),
+			expected: false,
+		},
+		{
+			name: "affinity terms are ORed",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "env", Operator: corev1.NodeSelectorOpIn, Values: []string{"dev"}},
+					}},
+					corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "zone", Operator: corev1.NodeSelectorOpExists},
+					}},
+				),
+			},
+			node:     testNode("node1", map[string]string{"env": "prod", "zone": "a"}),
+			expected: true,
+		},
+		{
+			name: "affinity expressions in a term are ANDed",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "env", Operator: corev1.NodeSelectorOpIn, Values: []string{"prod"}},
+						{Key: "zone", Operator: corev1.NodeSelectorOpDoesNotExist},
+					}},
+				),
+			},
+			node:     testNode("node1", map[string]string{"env": "prod", "zone": "a"}),
+			expected: false,
+		},
+		{
+			name: "affinity gt operator",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "cores", Operator: corev1.NodeSelectorOpGt, Values: []string{"8"}},
+					}},
+				),
+			},
+			node:     testNode("node1", map[string]string{"cores": "16"}),
+			expected: true,
+		},
+		{
+			name: "affinity matches node name field",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{
+						{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node1", "node2"}},
+					}},
+				),
+			},
+			node:     testNode("node2", nil),
+			expected: true,
+		},
+		{
+			name: "affinity does not match node name field",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{
+						{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node1"}},
+					}},
+				),
+			},
+			node:     testNode("node2", nil),
+			expected: false,
+		},
+		{
+			name: "empty affinity terms match no nodes",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(corev1.NodeSelectorTerm{}),
+			},
+			node:     testNode("node1", map[string]string{"env": "prod"}),
+			expected: false,
+		},
+		{
+			name: "preferred affinity is ignored",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: &corev1.NodeAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+						{Weight: 1, Preference: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: "env", Operator: corev1.NodeSelectorOpIn, Values: []string{"dev"}},
+						}}},
+					},
+				},
+			},
+			node:     testNode("node1", map[string]string{"env": "prod"}),
+			expected: true,
+		},
+		{
+			name: "node selector and affinity are ANDed",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeSelector: []coralv1beta1.NodeSelector{
+					{Key: "env", Operator: selection.In, Values: []string{"dev"}},
+				},
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "env", Operator: corev1.NodeSelectorOpIn, Values: []string{"prod"}},
+					}},
+				),
+			},
+			node:     testNode("node1", map[string]string{"env": "prod"}),
+			expected: false,
+		},
+		{
+			name:     "taints are ignored without tolerations",
+			node:     testNode("node1", nil, gpuTaint),
+			expected: true,
+		},
+		{
+			name: "tolerated taints match",
+			spec: coralv1beta1.ImageSyncSpec{
+				Tolerations: []corev1.Toleration{
+					{Key: "gpu", Operator: corev1.TolerationOpEqual, Value: "true", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+			node:     testNode("node1", nil, gpuTaint, softTaint),
+			expected: true,
+		},
+		{
+			name: "untolerated taints do not match",
+			spec: coralv1beta1.ImageSyncSpec{
+				Tolerations: []corev1.Toleration{
+					{Key: "dedicated", Operator: corev1.TolerationOpExists},
+				},
+			},
+			node:     testNode("node1", nil, gpuTaint),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := NodeMatches(tt.node, &coralv1beta1.ImageSync{Spec: tt.spec})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+}
+
+func TestNewNodeMatcher_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec coralv1beta1.ImageSyncSpec
+	}{
+		{
+			name: "invalid node selector operator",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeSelector: []coralv1beta1.NodeSelector{
+					{Key: "env", Operator: selection.Operator("InvalidOp"), Values: []string{"prod"}},
+				},
+			},
+		},
+		{
+			name: "invalid affinity operator",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "env", Operator: corev1.NodeSelectorOperator("Near"), Values: []string{"prod"}},
+					}},
+				),
+			},
+		},
+		{
+			name: "unsupported field key",
+			spec: coralv1beta1.ImageSyncSpec{
+				NodeAffinity: requiredAffinity(
+					corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{
+						{Key: "spec.unschedulable", Operator: corev1.NodeSelectorOpIn, Values: []string{"false"}},
+					}},
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNodeMatcher(&coralv1beta1.ImageSync{Spec: tt.spec})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewPodNodeMatcher(t *testing.T) {
+	taint := corev1.Taint{Key: "gpu", Effect: corev1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name     string
+		spec     corev1.PodSpec
+		node     *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "node selector matches",
+			spec:     corev1.PodSpec{NodeSelector: map[string]string{"role": "app"}},
+			node:     testNode("node1", map[string]string{"role": "app"}),
+			expected: true,
+		},
+		{
+			name:     "taints are always considered",
+			node:     testNode("node1", nil, taint),
+			expected: false,
+		},
+		{
+			name: "tolerated taints match",
+			spec: corev1.PodSpec{Tolerations: []corev1.Toleration{
+				{Key: "gpu", Operator: corev1.TolerationOpExists},
+			}},
+			node:     testNode("node1", nil, taint),
+			expected: true,
+		},
+		{
+			name:     "node name restricts the node",
+			spec:     corev1.PodSpec{NodeName: "node2"},
+			node:     testNode("node1", nil),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewPodNodeMatcher(&tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, m.Matches(tt.node))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/containers/image/v5/docker/reference"
+)
+
+// Reference is a normalized container image reference.  Short names are expanded to the
+// default docker.io registry and library namespace, references without a tag or a digest
+// are given the latest tag, and the tag is dropped from references that have both a tag
+// and a digest as the digest is what will be pulled.
+type Reference struct {
+	named reference.Named
+}
+
+// ParseReference parses and normalizes an image reference.
+func ParseReference(image string) (Reference, error) {
+	named, err := reference.ParseDockerRef(image)
+	if err != nil {
+		return Reference{}, fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+
+	// The parser accepts hostnames that are not valid path components (e.g. with
+	// underscores) but cannot split them into a domain, so reject them here.
+	if reference.Domain(named) == "" {
+		return Reference{}, fmt.Errorf("invalid image reference %q: invalid registry hostname", image)
+	}
+
+	return Reference{named: named}, nil
+}
+
+// MustParseReference parses an image reference and panics if it is invalid.
+func MustParseReference(image string) Reference {
+	ref, err := ParseReference(image)
+	if err != nil {
+		panic(err)
+	}
+
+	return ref
+}
+
+// String returns the fully qualified reference including the tag and/or digest.
+func (r Reference) String() string {
+	if r.named == nil {
+		return ""
+	}
+
+	return r.named.String()
+}
+
+// Domain returns the registry hostname, including the port if present.
+func (r Reference) Domain() string {
+	return reference.Domain(r.named)
+}
+
+// Path returns the repository path without the registry hostname.
+func (r Reference) Path() string {
+	return reference.Path(r.named)
+}
+
+// Repository returns the fully qualified repository without the tag or digest.
+func (r Reference) Repository() string {
+	return r.named.Name()
+}
+
+// Tag returns the tag or an empty string if the reference only has a digest.
+func (r Reference) Tag() string {
+	if tagged, ok := r.named.(reference.Tagged); ok {
+		return tagged.Tag()
+	}
+
+	return ""
+}
+
+// Digest returns the digest or an empty string if the reference does not have one.
+func (r Reference) Digest() string {
+	if digested, ok := r.named.(reference.Digested); ok {
+		return digested.Digest().String()
+	}
+
+	return ""
+}
+
+// IsDigested returns true if the reference is pinned to a digest.
+func (r Reference) IsDigested() bool {
+	return r.Digest() != ""
+}
+
+// Name returns the repository path along with the tag and/or digest, but without the registry
+// hostname.  It is used to place the image under a different registry.
+func (r Reference) Name() string {
+	name := r.Path()
+	if tag := r.Tag(); tag != "" {
+		name += ":" + tag
+	}
+
+	if digest := r.Digest(); digest != "" {
+		name += "@" + digest
+	}
+
+	return name
+}
+
+// WithDigest returns the repository pinned to the digest.
+func (r Reference) WithDigest(digest string) string {
+	return r.Repository() + "@" + digest
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name       string
+		image      string
+		want       string
+		domain     string
+		path       string
+		repository string
+		tag        string
+		digest     string
+		nameOnly   string
+	}{
+		{
+			name:       "short name",
+			image:      "nginx",
+			want:       "docker.io/library/nginx:latest",
+			domain:     "docker.io",
+			path:       "library/nginx",
+			repository: "docker.io/library/nginx",
+			tag:        "latest",
+			nameOnly:   "library/nginx:latest",
+		},
+		{
+			name:       "namespaced name with tag",
+			image:      "org/app:v1",
+			want:       "docker.io/org/app:v1",
+			domain:     "docker.io",
+			path:       "org/app",
+			repository: "docker.io/org/app",
+			tag:        "v1",
+			nameOnly:   "org/app:v1",
+		},
+		{
+			name:       "digest only",
+			image:      "nginx@" + testDigest,
+			want:       "docker.io/library/nginx@" + testDigest,
+			domain:     "docker.io",
+			path:       "library/nginx",
+			repository: "docker.io/library/nginx",
+			digest:     testDigest,
+			nameOnly:   "library/nginx@" + testDigest,
+		},
+		{
+			name:       "tag and digest drops the tag",
+			image:      "nginx:1.25@" + testDigest,
+			want:       "docker.io/library/nginx@" + testDigest,
+			domain:     "docker.io",
+			path:       "library/nginx",
+			repository: "docker.io/library/nginx",
+			digest:     testDigest,
+			nameOnly:   "library/nginx@" + testDigest,
+		},
+		{
+			name:       "localhost with port and no tag",
+			image:      "localhost:5000/foo",
+			want:       "localhost:5000/foo:latest",
+			domain:     "localhost:5000",
+			path:       "foo",
+			repository: "localhost:5000/foo",
+			tag:        "latest",
+			nameOnly:   "foo:latest",
+		},
+		{
+			name:       "host with port, namespace and tag",
+			image:      "registry.example.com:5000/ns/img:tag",
+			want:       "registry.example.com:5000/ns/img:tag",
+			domain:     "registry.example.com:5000",
+			path:       "ns/img",
+			repository: "registry.example.com:5000/ns/img",
+			tag:        "tag",
+			nameOnly:   "ns/img:tag",
+		},
+		{
+			name:       "ip address registry",
+			image:      "192.168.1.100:5000/nginx",
+			want:       "192.168.1.100:5000/nginx:latest",
+			domain:     "192.168.1.100:5000",
+			path:       "nginx",
+			repository: "192.168.1.100:5000/nginx",
+			tag:        "latest",
+			nameOnly:   "nginx:latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseReference(tt.image)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, ref.String())
+			assert.Equal(t, tt.domain, ref.Domain())
+			assert.Equal(t, tt.path, ref.Path())
+			assert.Equal(t, tt.repository, ref.Repository())
+			assert.Equal(t, tt.tag, ref.Tag())
+			assert.Equal(t, tt.digest, ref.Digest())
+			assert.Equal(t, tt.digest != "", ref.IsDigested())
+			assert.Equal(t, tt.nameOnly, ref.Name())
+		})
+	}
+}
+
+func TestParseReference_invalid(t *testing.T) {
+	for _, image := range []string{
+		"",
+		"Nginx",
+		"nginx:bad tag",
+		"nginx@sha256:abc123",
+		"my_registry.example.com/nginx",
+	} {
+		_, err := ParseReference(image)
+		assert.Error(t, err, "ParseReference(%q) should fail", image)
+	}
+}
+
+func TestReference_WithDigest(t *testing.T) {
+	ref := MustParseReference("localhost:5000/foo:v1")
+	assert.Equal(t, "localhost:5000/foo@"+testDigest, ref.WithDigest(testDigest))
+}
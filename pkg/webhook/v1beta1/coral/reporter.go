@@ -31,7 +31,16 @@ func (s *Service) Reporter(ctx context.Context, req *connect.Request[coralv1beta
 	)
 	logger.V(6).Info("received request", "request", req)
 
+	failed := make(map[string]string)
+	for _, img := range req.Msg.GetImages() {
+		if img.GetState() == coralv1beta1.ImageState_IMAGE_STATE_FAILED {
+			failed[img.GetImage()] = img.GetReason()
+		}
+	}
+
 	s.nodeRef.AddImages(req.Msg.GetNode(), req.Msg.GetImage())
+	s.nodeRef.SetPinned(req.Msg.GetNode(), req.Msg.GetPinned())
+	s.nodeRef.SetFailed(req.Msg.GetNode(), failed)
 
 	return connect.NewResponse(&coralv1beta1.ReporterResponse{
 		Message: "ok",
@@ -0,0 +1,69 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagesync
+
+import (
+	"context"
+	"fmt"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ClusterWebhook is the defaulting and validating webhook for the clusterimagesyncs.
+type ClusterWebhook struct{}
+
+func (w *ClusterWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &coralv1beta1.ClusterImageSync{}).
+		WithValidator(w).
+		WithDefaulter(w).
+		Complete()
+}
+
+func (w *ClusterWebhook) Default(ctx context.Context, obj *coralv1beta1.ClusterImageSync) error {
+	coralv1beta1.Defaulted(obj)
+	return nil
+}
+
+// ValidateCreate implements webhook Validator.
+func (w *ClusterWebhook) ValidateCreate(ctx context.Context, obj *coralv1beta1.ClusterImageSync) (admission.Warnings, error) {
+	return nil, validatePullSecrets(obj)
+}
+
+// ValidateUpdate implements webhook Validator.
+func (w *ClusterWebhook) ValidateUpdate(ctx context.Context, old *coralv1beta1.ClusterImageSync, new *coralv1beta1.ClusterImageSync) (admission.Warnings, error) {
+	return nil, validatePullSecrets(new)
+}
+
+// ValidateDelete implements webhook Validator.
+func (w *ClusterWebhook) ValidateDelete(ctx context.Context, obj *coralv1beta1.ClusterImageSync) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validatePullSecrets ensures that the pull secrets reference both a namespace and a name as
+// there is no namespace to default to for a cluster scoped resource.
+func validatePullSecrets(obj *coralv1beta1.ClusterImageSync) error {
+	for i, ref := range obj.Spec.ImagePullSecrets {
+		if ref.Name == "" || ref.Namespace == "" {
+			return fmt.Errorf("spec.imagePullSecrets[%d]: both namespace and name are required", i)
+		}
+	}
+
+	return nil
+}
+
+var _ admission.Defaulter[*coralv1beta1.ClusterImageSync] = &ClusterWebhook{}
+var _ admission.Validator[*coralv1beta1.ClusterImageSync] = &ClusterWebhook{}
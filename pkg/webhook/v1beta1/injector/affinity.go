@@ -0,0 +1,223 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injector
+
+import (
+	"slices"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const (
+	// AffinityPreferred adds preferred node affinity terms.  This is the default.
+	AffinityPreferred = "preferred"
+	// AffinityRequired adds the terms to the required node affinity.
+	AffinityRequired = "required"
+	// AffinityNone disables the affinity injection.
+	AffinityNone = "none"
+
+	// AffinityWeight is the weight of the preferred terms.
+	AffinityWeight = 100
+	// MaxAffinityTerms limits the number of terms that are generated when combining the
+	// selectors of the imagesyncs for multiple images.
+	MaxAffinityTerms = 16
+)
+
+// injectAffinity adds node affinity terms to the spec that select the nodes where all of the
+// images that are covered by the imagesyncs are synced to.  Images that are not listed in
+// any imagesync, or that are synced to every node, do not constrain the nodes.
+func injectAffinity(spec *corev1.PodSpec, mode string, isyncs []coralv1beta1.ImageSyncObject, images []string) {
+	if mode == AffinityNone {
+		return
+	}
+
+	terms := affinityTerms(isyncs, images)
+	if len(terms) == 0 {
+		return
+	}
+
+	if spec.Affinity == nil {
+		spec.Affinity = &corev1.Affinity{}
+	}
+
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+
+	affinity := spec.Affinity.NodeAffinity
+
+	if mode == AffinityRequired {
+		required := affinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if required == nil || len(required.NodeSelectorTerms) == 0 {
+			affinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+				NodeSelectorTerms: terms,
+			}
+			return
+		}
+
+		required.NodeSelectorTerms = product(required.NodeSelectorTerms, terms)
+		return
+	}
+
+	for _, term := range terms {
+		affinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			affinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			corev1.PreferredSchedulingTerm{Weight: AffinityWeight, Preference: term},
+		)
+	}
+}
+
+// affinityTerms returns the ORed terms that select the nodes holding all of the images.  Nil
+// is returned if the images do not constrain the nodes or if the number of terms would
+// exceed MaxAffinityTerms.
+func affinityTerms(isyncs []coralv1beta1.ImageSyncObject, images []string) []corev1.NodeSelectorTerm {
+	var terms []corev1.NodeSelectorTerm
+	for _, img := range images {
+		imgTerms, ok := imageTerms(isyncs, img)
+		if !ok {
+			continue
+		}
+
+		if terms == nil {
+			terms = imgTerms
+		} else {
+			terms = product(terms, imgTerms)
+		}
+
+		if len(terms) == 0 || len(terms) > MaxAffinityTerms {
+			return nil
+		}
+	}
+
+	return terms
+}
+
+// imageTerms returns the ORed terms of the imagesyncs that list the image.  False is returned
+// if the image is not listed or if one of the imagesyncs syncs it to every node.
+func imageTerms(isyncs []coralv1beta1.ImageSyncObject, img string) ([]corev1.NodeSelectorTerm, bool) {
+	var terms []corev1.NodeSelectorTerm
+	for _, isync := range isyncs {
+		if !lists(isync, img) {
+			continue
+		}
+
+		isyncTerms, all, ok := nodeSelectorTerms(isync)
+		if !ok {
+			continue
+		}
+
+		if all {
+			return nil, false
+		}
+
+		terms = append(terms, isyncTerms...)
+	}
+
+	return terms, len(terms) > 0
+}
+
+// nodeSelectorTerms converts the selectors of the imagesync to node selector terms.  The
+// all return is true if the imagesync selects every node.  The ok return is false if the
+// selectors can not be converted.
+func nodeSelectorTerms(isync coralv1beta1.ImageSyncObject) (terms []corev1.NodeSelectorTerm, all bool, ok bool) {
+	base := make([]corev1.NodeSelectorRequirement, 0, len(isync.GetNodeSelector()))
+	for _, v := range isync.GetNodeSelector() {
+		op, found := operators[v.Operator]
+		if !found {
+			return nil, false, false
+		}
+
+		base = append(base, corev1.NodeSelectorRequirement{Key: v.Key, Operator: op, Values: v.Values})
+	}
+
+	affinity := isync.GetNodeAffinity()
+	if affinity == nil || affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		if len(base) == 0 {
+			return nil, true, true
+		}
+
+		return []corev1.NodeSelectorTerm{{MatchExpressions: base}}, false, true
+	}
+
+	for _, term := range affinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		// Empty terms match no nodes.
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		terms = append(terms, merge(corev1.NodeSelectorTerm{MatchExpressions: base}, term))
+	}
+
+	return terms, false, true
+}
+
+// product ANDs two lists of ORed terms.
+func product(a, b []corev1.NodeSelectorTerm) []corev1.NodeSelectorTerm {
+	terms := make([]corev1.NodeSelectorTerm, 0, len(a)*len(b))
+	for _, x := range a {
+		for _, y := range b {
+			terms = append(terms, merge(x, y))
+		}
+	}
+
+	return terms
+}
+
+// merge ANDs two terms, dropping the requirements that the terms have in common.
+func merge(a, b corev1.NodeSelectorTerm) corev1.NodeSelectorTerm {
+	return corev1.NodeSelectorTerm{
+		MatchExpressions: appendUnique(appendUnique(nil, a.MatchExpressions), b.MatchExpressions),
+		MatchFields:      appendUnique(appendUnique(nil, a.MatchFields), b.MatchFields),
+	}
+}
+
+func appendUnique(reqs, others []corev1.NodeSelectorRequirement) []corev1.NodeSelectorRequirement {
+	for _, other := range others {
+		if !slices.ContainsFunc(reqs, func(req corev1.NodeSelectorRequirement) bool {
+			return equality.Semantic.DeepEqual(req, other)
+		}) {
+			reqs = append(reqs, other)
+		}
+	}
+
+	return reqs
+}
+
+// lists returns true if the imagesync lists the normalized image.
+func lists(isync coralv1beta1.ImageSyncObject, img string) bool {
+	for _, v := range isync.GetImages() {
+		if ref, err := util.ParseReference(v); err == nil && ref.String() == img {
+			return true
+		}
+	}
+
+	return false
+}
+
+var operators = map[selection.Operator]corev1.NodeSelectorOperator{
+	selection.In:           corev1.NodeSelectorOpIn,
+	selection.Equals:       corev1.NodeSelectorOpIn,
+	selection.DoubleEquals: corev1.NodeSelectorOpIn,
+	selection.NotIn:        corev1.NodeSelectorOpNotIn,
+	selection.NotEquals:    corev1.NodeSelectorOpNotIn,
+	selection.Exists:       corev1.NodeSelectorOpExists,
+	selection.DoesNotExist: corev1.NodeSelectorOpDoesNotExist,
+	selection.GreaterThan:  corev1.NodeSelectorOpGt,
+	selection.LessThan:     corev1.NodeSelectorOpLt,
+}
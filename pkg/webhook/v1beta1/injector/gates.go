@@ -0,0 +1,93 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injector
+
+import (
+	"slices"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// gateAnnotations are the workload annotations that are copied to the pod template so that
+// the pods are gated when they are created.
+var gateAnnotations = []string{ //nolint:gochecknoglobals
+	coralv1beta1.ImageSyncEnableAnnotation,
+	coralv1beta1.ImageSyncWarmAnnotation,
+	coralv1beta1.ImageSyncWarmTimeoutAnnotation,
+	coralv1beta1.ImageSyncMinNodesAnnotation,
+	coralv1beta1.ImageSyncMaxWaitAnnotation,
+}
+
+// propagateGates copies the gate annotations of the workload to the pod template.
+func propagateGates(annotations map[string]string, template *metav1.ObjectMeta) {
+	_, available := annotations[coralv1beta1.ImageSyncMinNodesAnnotation]
+	if annotations[coralv1beta1.ImageSyncWarmAnnotation] != "true" && !available {
+		return
+	}
+
+	for _, key := range gateAnnotations {
+		if value, ok := annotations[key]; ok {
+			template.Annotations = setAnnotation(template.Annotations, key, value)
+		}
+	}
+}
+
+// injectGates adds the coral scheduling gates to a new pod.  Pods of deployments and
+// statefulsets that opt in with the warm annotation are held until their images have been
+// synced to the nodes.  Pods that set the min-nodes annotation are held until enough of the
+// nodes that they can be scheduled on hold their images.  True is returned if a gate was
+// added.
+func injectGates(obj client.Object) bool {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+
+	added := false
+	if pod.Annotations[coralv1beta1.ImageSyncWarmAnnotation] == "true" && rollout(pod) {
+		added = addGate(pod, coralv1beta1.ImageSyncWarmingGate) || added
+	}
+
+	if _, ok := pod.Annotations[coralv1beta1.ImageSyncMinNodesAnnotation]; ok {
+		added = addGate(pod, coralv1beta1.ImageSyncAvailableGate) || added
+	}
+
+	return added
+}
+
+// rollout returns true if the pod is controlled by a replicaset or a statefulset.
+func rollout(pod *corev1.Pod) bool {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.APIVersion != appsv1.SchemeGroupVersion.String() {
+		return false
+	}
+
+	return owner.Kind == "ReplicaSet" || owner.Kind == "StatefulSet"
+}
+
+func addGate(pod *corev1.Pod, name string) bool {
+	if slices.ContainsFunc(pod.Spec.SchedulingGates, func(gate corev1.PodSchedulingGate) bool {
+		return gate.Name == name
+	}) {
+		return false
+	}
+
+	pod.Spec.SchedulingGates = append(pod.Spec.SchedulingGates, corev1.PodSchedulingGate{Name: name})
+	return true
+}
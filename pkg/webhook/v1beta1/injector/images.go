@@ -0,0 +1,136 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injector
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// mirroredImages returns the status of the images that the mirrors in the namespace have
+// successfully copied to the coral registry keyed by the normalized source image.
+func mirroredImages(ctx context.Context, c client.Reader, namespace string) (map[string]coralv1beta1.MirrorImage, error) {
+	var mirrors coralv1beta1.MirrorList
+	if err := c.List(ctx, &mirrors, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	mirrored := make(map[string]coralv1beta1.MirrorImage)
+	for _, mirror := range mirrors.Items {
+		if !mirror.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		for _, image := range mirror.Status.Images {
+			if image.LastSyncTime != nil {
+				mirrored[image.Image] = image
+			}
+		}
+	}
+
+	return mirrored, nil
+}
+
+// resolvedDigests returns the digests that the imagesyncs resolved for their images keyed by
+// the normalized image.  Only digests that the nodes have verified, by having the image
+// available at the digest, are returned.
+func resolvedDigests(isyncs []coralv1beta1.ImageSyncObject) map[string]string {
+	digests := make(map[string]string)
+	for _, isync := range isyncs {
+		status := isync.GetImageSyncStatus()
+		if status.ObservedGeneration != isync.GetGeneration() {
+			continue
+		}
+
+		for _, image := range status.Images {
+			if image.Digest != "" && image.Available > 0 {
+				digests[image.Image] = image.Digest
+			}
+		}
+	}
+
+	return digests
+}
+
+// resolveImages rewrites the container images to the copies in the registry when mirrored
+// is set, and pins them to their digests when digests is set.  Images that are rewritten are
+// pinned to the digest of the copy, the others to the digest resolved by the imagesyncs.  The
+// pinned images are returned keyed by the container name in the form <image>:<tag>@<digest>,
+// along with the normalized original image of every container that was changed.
+func resolveImages(
+	containers []*corev1.Container,
+	mirrored map[string]coralv1beta1.MirrorImage,
+	registry string,
+	digests map[string]string,
+) (map[string]string, map[string]string) {
+	pinned := make(map[string]string)
+	original := make(map[string]string)
+	for _, container := range containers {
+		ref, err := util.ParseReference(container.Image)
+		if err != nil {
+			continue
+		}
+
+		repository, digest := ref.Repository(), digests[ref.String()]
+		if image, ok := mirrored[ref.String()]; ok {
+			container.Image = registry + "/" + ref.Name()
+			repository, digest = registry+"/"+ref.Path(), image.Digest
+			original[container.Name] = ref.String()
+		}
+
+		if digests == nil || digest == "" || ref.IsDigested() {
+			continue
+		}
+
+		container.Image = repository + "@" + digest
+		pinned[container.Name] = ref.String() + "@" + digest
+		original[container.Name] = ref.String()
+	}
+
+	return pinned, original
+}
+
+// containerAnnotation merges the container images into the value of an annotation in the
+// form <container>=<image>.  The entries of containers that are not in the spec anymore are
+// dropped.
+func containerAnnotation(value string, images map[string]string, spec *corev1.PodSpec) string {
+	entries := util.ParseContainerImages(value)
+	for name, image := range images {
+		entries[name] = image
+	}
+
+	names := make(map[string]bool)
+	for _, list := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range list {
+			names[container.Name] = true
+		}
+	}
+
+	parts := make([]string, 0, len(entries))
+	for name, image := range entries {
+		if names[name] {
+			parts = append(parts, name+"="+image)
+		}
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, ",")
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"net/http"
 
+	"ctx.sh/coral/pkg/store"
 	"github.com/go-logr/logr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -28,24 +29,41 @@ import (
 
 // +kubebuilder:docs-gen:collapse=Go imports
 
+type Options struct {
+	// Registry is the address of the coral registry that the nodes pull the mirrored
+	// images from.  Images are not rewritten when it is empty.
+	Registry string
+	// NodeRef holds the images that are available on each node.  Availability is not
+	// checked when it is nil.
+	NodeRef *store.NodeRef
+	// Templates are the pod template paths of the custom resources that are injected.
+	Templates TemplatePaths
+}
+
 type Injector struct {
 	client.Client
-	cache   cache.Cache
-	decoder admission.Decoder
-	log     logr.Logger
+	cache     cache.Cache
+	decoder   admission.Decoder
+	log       logr.Logger
+	registry  string
+	nodeRef   *store.NodeRef
+	templates TemplatePaths
 
 	// default webhook action as config value
 	defaultAction admission.Response
 }
 
 // SetupWebhookWithManager adds webhook for the resource injector.
-func SetupWebhookWithManager(mgr ctrl.Manager) error {
+func SetupWebhookWithManager(mgr ctrl.Manager, opts *Options) error {
 	i := &Injector{
 		Client:        mgr.GetClient(),
 		cache:         mgr.GetCache(),
 		decoder:       admission.NewDecoder(mgr.GetScheme()),
 		defaultAction: admission.Allowed(""),
 		log:           mgr.GetLogger().WithName("image-injector"),
+		registry:      opts.Registry,
+		nodeRef:       opts.NodeRef,
+		templates:     opts.Templates,
 	}
 
 	mgr.GetWebhookServer().Register("/inject-coral-ctx-sh-v1beta1-imagesync", &webhook.Admission{
@@ -59,7 +77,7 @@ func (i *Injector) Handle(ctx context.Context, req admission.Request) admission.
 	log := ctrl.LoggerFrom(ctx)
 	log.V(6).Info("handling request", "req", req)
 
-	mutator, err := FromReq(req, i.decoder)
+	mutator, err := FromReq(req, i.decoder, i.templates)
 	if err != nil {
 		return admission.Errored(http.StatusBadRequest, err)
 	}
@@ -69,7 +87,7 @@ func (i *Injector) Handle(ctx context.Context, req admission.Request) admission.
 		return admission.Allowed("")
 	}
 
-	return mutator.Mutate(req)
+	return mutator.WithRegistry(i.registry).WithNodeRef(i.nodeRef).Mutate(ctx, i.Client, req)
 }
 
 var _ admission.Handler = &Injector{}
@@ -15,10 +15,20 @@
 package injector
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/store"
+	"ctx.sh/coral/pkg/util"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -32,20 +42,35 @@ type Mutator struct {
 	enabled  bool
 	injected bool
 	kind     string
+	registry string
+	nodeRef  *store.NodeRef
 	obj      client.Object
+	template *unstructuredTemplate
 }
 
-func FromReq(req admission.Request, decoder admission.Decoder) (*Mutator, error) {
+// FromReq decodes the object of the request.  Kinds with a configured template path are
+// decoded as unstructured objects and their pod template is located by the path.
+func FromReq(req admission.Request, decoder admission.Decoder, paths TemplatePaths) (*Mutator, error) {
 	m := &Mutator{}
 
 	m.kind = req.Kind.Kind
 
-	obj, err := ObjectFromKind(m.kind)
-	if err != nil {
-		return nil, err
+	gvk := schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind}
+	path, custom := paths[gvk]
+
+	var obj client.Object
+	if custom {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		obj = u
+	} else {
+		var err error
+		if obj, err = ObjectFromKind(m.kind); err != nil {
+			return nil, err
+		}
 	}
 
-	err = decoder.Decode(req, obj)
+	err := decoder.Decode(req, obj)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +90,12 @@ func FromReq(req admission.Request, decoder admission.Decoder) (*Mutator, error)
 		m.injected = true
 	}
 
+	if custom {
+		if m.template, err = newUnstructuredTemplate(obj.(*unstructured.Unstructured), path); err != nil {
+			return nil, err
+		}
+	}
+
 	return m, nil
 }
 
@@ -72,8 +103,102 @@ func (m *Mutator) Managed() bool {
 	return m.enabled
 }
 
-func (m *Mutator) Mutate(req admission.Request) admission.Response {
-	obj := m.mutate(m.obj)
+// WithRegistry sets the address of the coral registry that images are rewritten to.  Images
+// are not rewritten when the registry is not set.
+func (m *Mutator) WithRegistry(registry string) *Mutator {
+	m.registry = registry
+	return m
+}
+
+// WithNodeRef sets the node references used to check that the images are available on the
+// nodes.
+func (m *Mutator) WithNodeRef(nodeRef *store.NodeRef) *Mutator {
+	m.nodeRef = nodeRef
+	return m
+}
+
+// Mutate rewrites the pull policy of the selected containers, steers the pods toward the
+// nodes that hold the images and returns the patch for the object.  Objects that were created
+// from an already injected template are left alone.
+func (m *Mutator) Mutate(ctx context.Context, c client.Reader, req admission.Request) admission.Response {
+	// The containers of an existing pod are immutable apart from their images, so pods are
+	// only injected when they are created.  Patching the pull policy on update would cause
+	// the update to be rejected.
+	if _, ok := m.obj.(*corev1.Pod); ok && req.Operation == admissionv1.Update {
+		return admission.Allowed("pods are only injected on create")
+	}
+
+	if m.injected && req.Operation == admissionv1.Create {
+		// Pods created from an injected template only need their scheduling gates.
+		if !injectGates(m.obj) {
+			return admission.Allowed("already injected")
+		}
+
+		return m.patch(req, m.obj)
+	}
+
+	isyncs, nodes, err := observe(ctx, c, req.Namespace)
+	if err != nil {
+		// The injector is best effort, the workload is admitted unchanged rather than
+		// blocking it on a lookup failure.
+		ctrl.LoggerFrom(ctx).Error(err, "unable to determine the imagesyncs covering the workload")
+		return admission.Allowed("")
+	}
+
+	warnings := m.check(isyncs)
+	if len(warnings) > 0 && m.obj.GetAnnotations()[coralv1beta1.ImageSyncEnforceAnnotation] == "true" {
+		resp := admission.Denied(strings.Join(warnings, "; "))
+		resp.Warnings = warnings
+		return resp
+	}
+
+	obj := m.mutate(m.obj, isyncs, nodes)
+
+	if m.rewrites() || m.pins() {
+		var mirrored map[string]coralv1beta1.MirrorImage
+		if m.rewrites() {
+			if mirrored, err = mirroredImages(ctx, c, req.Namespace); err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "unable to determine the mirrored images")
+			}
+		}
+
+		m.resolve(obj, mirrored, isyncs)
+	}
+
+	// Scheduling gates can only be added when the pod is created.
+	if req.Operation == admissionv1.Create {
+		injectGates(obj)
+	}
+
+	return m.patch(req, obj).WithWarnings(warnings...)
+}
+
+// check returns the warnings for the images of the selected containers that have not been
+// prefetched.
+func (m *Mutator) check(isyncs []coralv1beta1.ImageSyncObject) admission.Warnings {
+	_, spec := m.podTemplate(m.obj)
+	if spec == nil {
+		return nil
+	}
+
+	return prefetchWarnings(selectContainers(spec, m.obj.GetAnnotations()), isyncs, m.nodeRef)
+}
+
+// podTemplate returns the pod template of the object.
+func (m *Mutator) podTemplate(obj client.Object) (*metav1.ObjectMeta, *corev1.PodSpec) {
+	if m.template != nil {
+		return &m.template.template.ObjectMeta, &m.template.template.Spec
+	}
+
+	return util.PodTemplate(obj)
+}
+
+func (m *Mutator) patch(req admission.Request, obj client.Object) admission.Response {
+	if m.template != nil {
+		if err := m.template.apply(); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
 
 	o, err := json.Marshal(obj)
 	if err != nil {
@@ -83,6 +208,212 @@ func (m *Mutator) Mutate(req admission.Request) admission.Response {
 	return admission.PatchResponseFromRaw(req.Object.Raw, o)
 }
 
-func (m *Mutator) mutate(obj client.Object) client.Object {
+func (m *Mutator) mutate(obj client.Object, isyncs []coralv1beta1.ImageSyncObject, nodes []corev1.Node) client.Object {
+	meta, spec := m.podTemplate(obj)
+	if spec == nil {
+		return obj
+	}
+
+	annotations := obj.GetAnnotations()
+	policy := pullPolicy(annotations[coralv1beta1.ImageSyncPullPolicyAnnotation])
+	containers := selectContainers(spec, annotations)
+
+	// The affinity is only added the first time the object is injected so that updates
+	// do not stack the terms.
+	if !m.injected {
+		injectAffinity(spec, annotations[coralv1beta1.ImageSyncAffinityAnnotation], isyncs, images(containers))
+	}
+
+	covered := coveredImages(spec, isyncs, nodes)
+	for _, container := range containers {
+		ref, err := util.ParseReference(container.Image)
+		if err != nil || !covered[ref.String()] {
+			continue
+		}
+
+		container.ImagePullPolicy = policy
+	}
+
+	annotations = setAnnotation(annotations, coralv1beta1.ImageSyncInjectedAnnotation, "true")
+	obj.SetAnnotations(annotations)
+
+	// Pods created from the template inherit the injected annotation so that they are not
+	// processed a second time.
+	if _, ok := obj.(*corev1.Pod); !ok {
+		meta.Annotations = setAnnotation(meta.Annotations, coralv1beta1.ImageSyncInjectedAnnotation, "true")
+		propagateGates(annotations, meta)
+	}
+
 	return obj
 }
+
+// rewrites returns true if the object asks for its images to be rewritten to the coral
+// registry and the registry address is known.
+func (m *Mutator) rewrites() bool {
+	return m.registry != "" && m.obj.GetAnnotations()[coralv1beta1.ImageSyncRewriteAnnotation] == "true"
+}
+
+// pins returns true if the object asks for its images to be pinned to their digests.
+func (m *Mutator) pins() bool {
+	return m.obj.GetAnnotations()[coralv1beta1.ImageSyncPinDigestsAnnotation] == "true"
+}
+
+// resolve points the selected containers at the copies of the mirrored images in the coral
+// registry and pins the images to their digests when requested.  The pinned images are
+// recorded in the pinned digests annotation and the images that the containers originally
+// referenced in the original images annotation.
+func (m *Mutator) resolve(obj client.Object, mirrored map[string]coralv1beta1.MirrorImage, isyncs []coralv1beta1.ImageSyncObject) {
+	meta, spec := m.podTemplate(obj)
+	if spec == nil {
+		return
+	}
+
+	var digests map[string]string
+	if m.pins() {
+		digests = resolvedDigests(isyncs)
+	}
+
+	pinned, original := resolveImages(selectContainers(spec, obj.GetAnnotations()), mirrored, m.registry, digests)
+	annotateContainers(obj, meta, spec, coralv1beta1.ImageSyncPinnedDigestsAnnotation, pinned)
+	annotateContainers(obj, meta, spec, coralv1beta1.ImageSyncOriginalImagesAnnotation, original)
+}
+
+// annotateContainers merges the container images into the annotation of the object and, for
+// workloads, of the pod template so that the pods carry the annotation.
+func annotateContainers(obj client.Object, meta *metav1.ObjectMeta, spec *corev1.PodSpec, key string, images map[string]string) {
+	if len(images) == 0 {
+		return
+	}
+
+	annotations := obj.GetAnnotations()
+	value := containerAnnotation(annotations[key], images, spec)
+	obj.SetAnnotations(setAnnotation(annotations, key, value))
+
+	if _, ok := obj.(*corev1.Pod); !ok {
+		meta.Annotations = setAnnotation(meta.Annotations, key, value)
+	}
+}
+
+// selectContainers returns the init containers and containers of the spec that are selected
+// by the include and exclude annotations.
+func selectContainers(spec *corev1.PodSpec, annotations map[string]string) []*corev1.Container {
+	include := util.SplitList(annotations[coralv1beta1.ImageSyncContainerIncludeAnnotation])
+	exclude := util.SplitList(annotations[coralv1beta1.ImageSyncContainerExcludeAnnotation])
+
+	var containers []*corev1.Container
+	for _, list := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range list {
+			name := list[i].Name
+			if (len(include) == 0 || include[name]) && !exclude[name] {
+				containers = append(containers, &list[i])
+			}
+		}
+	}
+
+	return containers
+}
+
+// observe returns the imagesyncs in the namespace and the clusterimagesyncs that are not
+// being deleted along with all of the nodes.
+func observe(ctx context.Context, c client.Reader, namespace string) ([]coralv1beta1.ImageSyncObject, []corev1.Node, error) {
+	isyncs, err := util.ListImageSyncsInScope(ctx, c, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nodes corev1.NodeList
+	if err := c.List(ctx, &nodes); err != nil {
+		return nil, nil, err
+	}
+
+	return isyncs, nodes.Items, nil
+}
+
+// coveredImages returns the normalized images of the imagesyncs that target every node the
+// pods with the spec could be scheduled on.
+func coveredImages(spec *corev1.PodSpec, isyncs []coralv1beta1.ImageSyncObject, nodes []corev1.Node) map[string]bool {
+	covered := make(map[string]bool)
+
+	podMatcher, err := util.NewPodNodeMatcher(spec)
+	if err != nil {
+		return covered
+	}
+
+	candidates := make([]*corev1.Node, 0, len(nodes))
+	for i := range nodes {
+		if podMatcher.Matches(&nodes[i]) {
+			candidates = append(candidates, &nodes[i])
+		}
+	}
+
+	if len(candidates) == 0 {
+		return covered
+	}
+
+	for _, isync := range isyncs {
+		if !targets(isync, candidates) {
+			continue
+		}
+
+		for _, img := range isync.GetImages() {
+			if ref, err := util.ParseReference(img); err == nil {
+				covered[ref.String()] = true
+			}
+		}
+	}
+
+	return covered
+}
+
+// images returns the normalized images of the containers.
+func images(containers []*corev1.Container) []string {
+	seen := make(map[string]bool)
+	refs := make([]string, 0, len(containers))
+	for _, container := range containers {
+		ref, err := util.ParseReference(container.Image)
+		if err != nil || seen[ref.String()] {
+			continue
+		}
+		seen[ref.String()] = true
+		refs = append(refs, ref.String())
+	}
+
+	return refs
+}
+
+// targets returns true if the imagesync targets all of the nodes.
+func targets(isync coralv1beta1.ImageSyncObject, nodes []*corev1.Node) bool {
+	matcher, err := util.NewNodeMatcher(isync)
+	if err != nil {
+		return false
+	}
+
+	for _, node := range nodes {
+		if !matcher.Matches(node) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pullPolicy returns the pull policy from the annotation value.  Images that are synced to
+// the nodes do not need to be pulled again, so IfNotPresent is used when the value is not
+// set or is not a valid policy.
+func pullPolicy(value string) corev1.PullPolicy {
+	switch policy := corev1.PullPolicy(value); policy {
+	case corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent:
+		return policy
+	default:
+		return corev1.PullIfNotPresent
+	}
+}
+
+func setAnnotation(annotations map[string]string, key, value string) map[string]string {
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+
+	return annotations
+}
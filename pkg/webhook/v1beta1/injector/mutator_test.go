@@ -0,0 +1,715 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/controller/workload"
+	"ctx.sh/coral/pkg/mock"
+	"ctx.sh/coral/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newClient() *mock.Client {
+	c := mock.NewClient().WithFixtureDirectory(filepath.Join("..", "..", "..", "..", "fixtures"))
+	c.ApplyFixtureOrDie("injector.yaml")
+
+	return c
+}
+
+func newDeployment(annotations map[string]string, nodeSelector map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeSelector: nodeSelector,
+					InitContainers: []corev1.Container{
+						{Name: "init", Image: "busybox:1.36", ImagePullPolicy: corev1.PullAlways},
+					},
+					Containers: []corev1.Container{
+						{Name: "web", Image: "docker.io/library/nginx:1.27", ImagePullPolicy: corev1.PullAlways},
+						{Name: "cache", Image: "redis:7", ImagePullPolicy: corev1.PullAlways},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newRequest(t *testing.T, obj runtime.Object, op admissionv1.Operation) admission.Request {
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Namespace: "default",
+			Operation: op,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func TestMutator_mutate(t *testing.T) {
+	enabled := map[string]string{coralv1beta1.ImageSyncEnableAnnotation: "true"}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		nodeSelector map[string]string
+		expected     []corev1.PullPolicy
+	}{
+		{
+			name:         "covered images on the target nodes are rewritten",
+			annotations:  enabled,
+			nodeSelector: map[string]string{"role": "app"},
+			expected:     []corev1.PullPolicy{corev1.PullIfNotPresent, corev1.PullIfNotPresent, corev1.PullAlways},
+		},
+		{
+			name:        "images are not rewritten when the imagesync does not cover all target nodes",
+			annotations: enabled,
+			expected:    []corev1.PullPolicy{corev1.PullAlways, corev1.PullAlways, corev1.PullAlways},
+		},
+		{
+			name: "excluded containers are not rewritten",
+			annotations: map[string]string{
+				coralv1beta1.ImageSyncEnableAnnotation:           "true",
+				coralv1beta1.ImageSyncContainerExcludeAnnotation: "init",
+			},
+			nodeSelector: map[string]string{"role": "app"},
+			expected:     []corev1.PullPolicy{corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullAlways},
+		},
+		{
+			name: "only included containers are rewritten with the annotated policy",
+			annotations: map[string]string{
+				coralv1beta1.ImageSyncEnableAnnotation:           "true",
+				coralv1beta1.ImageSyncContainerIncludeAnnotation: "web, cache",
+				coralv1beta1.ImageSyncPullPolicyAnnotation:       "Never",
+			},
+			nodeSelector: map[string]string{"role": "app"},
+			expected:     []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever, corev1.PullAlways},
+		},
+	}
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newClient()
+
+			deployment := newDeployment(tt.annotations, tt.nodeSelector)
+			m, err := FromReq(newRequest(t, deployment, admissionv1.Create), decoder, nil)
+			require.NoError(t, err)
+			require.True(t, m.Managed())
+
+			isyncs, nodes, err := observe(ctx, c, "default")
+			require.NoError(t, err)
+
+			obj := m.mutate(m.obj, isyncs, nodes).(*appsv1.Deployment)
+			spec := obj.Spec.Template.Spec
+			assert.Equal(t, tt.expected, []corev1.PullPolicy{
+				spec.InitContainers[0].ImagePullPolicy,
+				spec.Containers[0].ImagePullPolicy,
+				spec.Containers[1].ImagePullPolicy,
+			})
+			assert.Equal(t, "true", obj.Annotations[coralv1beta1.ImageSyncInjectedAnnotation])
+			assert.Equal(t, "true", obj.Spec.Template.Annotations[coralv1beta1.ImageSyncInjectedAnnotation])
+		})
+	}
+}
+
+func TestMutator_Mutate_injected(t *testing.T) {
+	deployment := newDeployment(map[string]string{
+		coralv1beta1.ImageSyncEnableAnnotation:   "true",
+		coralv1beta1.ImageSyncInjectedAnnotation: "true",
+	}, map[string]string{"role": "app"})
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+	c := newClient()
+
+	req := newRequest(t, deployment, admissionv1.Create)
+	m, err := FromReq(req, decoder, nil)
+	require.NoError(t, err)
+
+	resp := m.Mutate(context.Background(), c, req)
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+
+	// Updates are processed again so that changed images are picked up.
+	req = newRequest(t, deployment, admissionv1.Update)
+	m, err = FromReq(req, decoder, nil)
+	require.NoError(t, err)
+
+	resp = m.Mutate(context.Background(), c, req)
+	assert.True(t, resp.Allowed)
+	assert.NotEmpty(t, resp.Patches)
+}
+
+func TestMutator_Mutate_pod_update(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Annotations: map[string]string{
+				coralv1beta1.ImageSyncEnableAnnotation:   "true",
+				coralv1beta1.ImageSyncInjectedAnnotation: "true",
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{"role": "app"},
+			Containers: []corev1.Container{
+				{Name: "web", Image: "docker.io/library/nginx:1.27", ImagePullPolicy: corev1.PullAlways},
+			},
+		},
+	}
+
+	raw, err := json.Marshal(pod)
+	require.NoError(t, err)
+
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Namespace: "default",
+			Operation: admissionv1.Update,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+
+	m, err := FromReq(req, admission.NewDecoder(scheme.Scheme), nil)
+	require.NoError(t, err)
+
+	// The pull policy of an existing pod can not be changed, so updates are not patched.
+	resp := m.Mutate(context.Background(), newClient(), req)
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+}
+
+func TestMutator_mutate_affinity(t *testing.T) {
+	appTerm := corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: "role", Operator: corev1.NodeSelectorOpIn, Values: []string{"app"}},
+	}}
+
+	tests := []struct {
+		name      string
+		mode      string
+		injected  bool
+		preferred []corev1.PreferredSchedulingTerm
+		required  *corev1.NodeSelector
+		covered   corev1.PullPolicy
+	}{
+		{
+			name:      "preferred by default",
+			preferred: []corev1.PreferredSchedulingTerm{{Weight: AffinityWeight, Preference: appTerm}},
+			covered:   corev1.PullAlways,
+		},
+		{
+			name:     "required narrows the target nodes",
+			mode:     AffinityRequired,
+			required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{appTerm}},
+			covered:  corev1.PullIfNotPresent,
+		},
+		{
+			name:    "disabled",
+			mode:    AffinityNone,
+			covered: corev1.PullAlways,
+		},
+		{
+			name:     "not added to injected objects",
+			mode:     AffinityRequired,
+			injected: true,
+			covered:  corev1.PullAlways,
+		},
+	}
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newClient()
+
+			annotations := map[string]string{
+				coralv1beta1.ImageSyncEnableAnnotation:   "true",
+				coralv1beta1.ImageSyncAffinityAnnotation: tt.mode,
+			}
+			if tt.injected {
+				annotations[coralv1beta1.ImageSyncInjectedAnnotation] = "true"
+			}
+
+			m, err := FromReq(newRequest(t, newDeployment(annotations, nil), admissionv1.Update), decoder, nil)
+			require.NoError(t, err)
+
+			isyncs, nodes, err := observe(ctx, c, "default")
+			require.NoError(t, err)
+
+			obj := m.mutate(m.obj, isyncs, nodes).(*appsv1.Deployment)
+			spec := obj.Spec.Template.Spec
+			assert.Equal(t, tt.covered, spec.Containers[0].ImagePullPolicy)
+
+			if tt.preferred == nil && tt.required == nil {
+				assert.Nil(t, spec.Affinity)
+				return
+			}
+
+			require.NotNil(t, spec.Affinity)
+			assert.Equal(t, tt.preferred, spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+			assert.Equal(t, tt.required, spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		})
+	}
+}
+
+func TestAffinityTerms(t *testing.T) {
+	withSelector := func(name, value string, images ...string) coralv1beta1.ImageSyncObject {
+		return &coralv1beta1.ImageSync{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: coralv1beta1.ImageSyncSpec{
+				Images: images,
+				NodeSelector: []coralv1beta1.NodeSelector{
+					{Key: "pool", Operator: selection.In, Values: []string{value}},
+				},
+			},
+		}
+	}
+
+	term := func(values ...string) corev1.NodeSelectorTerm {
+		term := corev1.NodeSelectorTerm{}
+		for _, v := range values {
+			term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+				Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{v},
+			})
+		}
+		return term
+	}
+
+	isyncs := []coralv1beta1.ImageSyncObject{
+		withSelector("a", "a", "nginx"),
+		withSelector("b", "b", "nginx"),
+		withSelector("c", "c", "redis"),
+		&coralv1beta1.ImageSync{Spec: coralv1beta1.ImageSyncSpec{Images: []string{"busybox"}}},
+	}
+
+	tests := []struct {
+		name     string
+		images   []string
+		expected []corev1.NodeSelectorTerm
+	}{
+		{
+			name:     "imagesyncs listing the same image are ORed",
+			images:   []string{"docker.io/library/nginx:latest"},
+			expected: []corev1.NodeSelectorTerm{term("a"), term("b")},
+		},
+		{
+			name:     "images are ANDed",
+			images:   []string{"docker.io/library/nginx:latest", "docker.io/library/redis:latest"},
+			expected: []corev1.NodeSelectorTerm{term("a", "c"), term("b", "c")},
+		},
+		{
+			name:     "images on every node do not constrain",
+			images:   []string{"docker.io/library/busybox:latest", "docker.io/library/redis:latest"},
+			expected: []corev1.NodeSelectorTerm{term("c")},
+		},
+		{
+			name:   "unlisted images do not constrain",
+			images: []string{"docker.io/library/golang:latest"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, affinityTerms(isyncs, tt.images))
+		})
+	}
+}
+
+func TestInjectGates(t *testing.T) {
+	owner := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       "web-5d8f7c9b6",
+		Controller: ptr.To(true),
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		owners      []metav1.OwnerReference
+		expected    bool
+	}{
+		{
+			name:        "rollout pods that opt in are gated",
+			annotations: map[string]string{coralv1beta1.ImageSyncWarmAnnotation: "true"},
+			owners:      []metav1.OwnerReference{owner},
+			expected:    true,
+		},
+		{
+			name:     "pods that do not opt in are not gated",
+			owners:   []metav1.OwnerReference{owner},
+			expected: false,
+		},
+		{
+			name:        "standalone pods are not gated",
+			annotations: map[string]string{coralv1beta1.ImageSyncWarmAnnotation: "true"},
+			expected:    false,
+		},
+		{
+			name:        "pods that require available nodes are gated",
+			annotations: map[string]string{coralv1beta1.ImageSyncMinNodesAnnotation: "2"},
+			expected:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "web",
+					Annotations:     tt.annotations,
+					OwnerReferences: tt.owners,
+				},
+			}
+
+			assert.Equal(t, tt.expected, injectGates(pod))
+			// Adding the gate again is a no-op.
+			assert.False(t, injectGates(pod))
+		})
+	}
+}
+
+func TestPropagateGates(t *testing.T) {
+	deployment := newDeployment(map[string]string{
+		coralv1beta1.ImageSyncEnableAnnotation:      "true",
+		coralv1beta1.ImageSyncWarmAnnotation:        "true",
+		coralv1beta1.ImageSyncWarmTimeoutAnnotation: "2m",
+	}, nil)
+
+	m := &Mutator{enabled: true, obj: deployment}
+	obj := m.mutate(deployment, nil, nil).(*appsv1.Deployment)
+
+	assert.Equal(t, map[string]string{
+		coralv1beta1.ImageSyncEnableAnnotation:      "true",
+		coralv1beta1.ImageSyncInjectedAnnotation:    "true",
+		coralv1beta1.ImageSyncWarmAnnotation:        "true",
+		coralv1beta1.ImageSyncWarmTimeoutAnnotation: "2m",
+	}, obj.Spec.Template.Annotations)
+}
+
+func TestMutator_Mutate_rewrite(t *testing.T) {
+	deployment := newDeployment(map[string]string{
+		coralv1beta1.ImageSyncEnableAnnotation:  "true",
+		coralv1beta1.ImageSyncRewriteAnnotation: "true",
+	}, map[string]string{"role": "app"})
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+	c := newClient()
+
+	req := newRequest(t, deployment, admissionv1.Create)
+	m, err := FromReq(req, decoder, nil)
+	require.NoError(t, err)
+
+	resp := m.WithRegistry("coral.example.com:5000").Mutate(context.Background(), c, req)
+	assert.True(t, resp.Allowed)
+
+	spec := m.obj.(*appsv1.Deployment).Spec.Template.Spec
+	// Only nginx has been successfully mirrored.
+	assert.Equal(t, "busybox:1.36", spec.InitContainers[0].Image)
+	assert.Equal(t, "coral.example.com:5000/library/nginx:1.27", spec.Containers[0].Image)
+	assert.Equal(t, "redis:7", spec.Containers[1].Image)
+	// The pull policy is still rewritten for the image that was synced to the nodes.
+	assert.Equal(t, corev1.PullIfNotPresent, spec.Containers[0].ImagePullPolicy)
+
+	template := m.obj.(*appsv1.Deployment).Spec.Template
+	assert.Equal(t, "web=docker.io/library/nginx:1.27", template.Annotations[coralv1beta1.ImageSyncOriginalImagesAnnotation])
+}
+
+func TestMutator_Mutate_rewrite_without_registry(t *testing.T) {
+	deployment := newDeployment(map[string]string{
+		coralv1beta1.ImageSyncEnableAnnotation:  "true",
+		coralv1beta1.ImageSyncRewriteAnnotation: "true",
+	}, nil)
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+	req := newRequest(t, deployment, admissionv1.Create)
+	m, err := FromReq(req, decoder, nil)
+	require.NoError(t, err)
+
+	resp := m.Mutate(context.Background(), newClient(), req)
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, "docker.io/library/nginx:1.27", m.obj.(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestMutator_Mutate_warnings(t *testing.T) {
+	nodeRef := store.NewNodeRef()
+	nodeRef.AddImages("app1", []string{"docker.io/library/nginx:1.27"})
+
+	expected := []string{
+		"image docker.io/library/busybox:1.36 is not available on any node",
+		"image docker.io/library/redis:7 is not prefetched by any imagesync",
+	}
+
+	tests := []struct {
+		name    string
+		enforce bool
+		allowed bool
+	}{
+		{name: "warnings are returned with the patch", enforce: false, allowed: true},
+		{name: "enforce mode rejects the object", enforce: true, allowed: false},
+	}
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{coralv1beta1.ImageSyncEnableAnnotation: "true"}
+			if tt.enforce {
+				annotations[coralv1beta1.ImageSyncEnforceAnnotation] = "true"
+			}
+
+			req := newRequest(t, newDeployment(annotations, map[string]string{"role": "app"}), admissionv1.Create)
+			m, err := FromReq(req, decoder, nil)
+			require.NoError(t, err)
+
+			resp := m.WithNodeRef(nodeRef).Mutate(context.Background(), newClient(), req)
+			assert.Equal(t, tt.allowed, resp.Allowed)
+			assert.ElementsMatch(t, expected, resp.Warnings)
+			if !tt.allowed {
+				assert.Empty(t, resp.Patches)
+			}
+		})
+	}
+}
+
+func TestPrefetchWarnings(t *testing.T) {
+	isync := &coralv1beta1.ImageSync{
+		Spec: coralv1beta1.ImageSyncSpec{Images: []string{"nginx:1.27"}},
+	}
+
+	containers := []*corev1.Container{
+		{Name: "web", Image: "nginx:1.27"},
+		{Name: "sidecar", Image: "docker.io/library/nginx:1.27"},
+	}
+
+	// Availability is not checked without the node references.
+	assert.Empty(t, prefetchWarnings(containers, []coralv1beta1.ImageSyncObject{isync}, nil))
+
+	assert.Equal(t, admission.Warnings{"image docker.io/library/nginx:1.27 is not available on any node"},
+		prefetchWarnings(containers, []coralv1beta1.ImageSyncObject{isync}, store.NewNodeRef()))
+}
+
+func TestMutator_Mutate_template_path(t *testing.T) {
+	rollout := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata": map[string]any{
+			"name":      "app",
+			"namespace": "default",
+			"annotations": map[string]any{
+				coralv1beta1.ImageSyncEnableAnnotation: "true",
+			},
+		},
+		"spec": map[string]any{
+			"strategy": map[string]any{"canary": map[string]any{}},
+			"template": map[string]any{
+				"spec": map[string]any{
+					"nodeSelector": map[string]any{"role": "app"},
+					"containers": []any{
+						map[string]any{
+							"name":            "web",
+							"image":           "nginx:1.27",
+							"imagePullPolicy": "Always",
+							"unknownField":    "kept",
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	raw, err := json.Marshal(rollout)
+	require.NoError(t, err)
+
+	gvk := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+			Namespace: "default",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+
+	_, err = FromReq(req, decoder, nil)
+	assert.Error(t, err)
+
+	m, err := FromReq(req, decoder, TemplatePaths{gvk: {"spec", "template"}})
+	require.NoError(t, err)
+	require.True(t, m.Managed())
+
+	resp := m.Mutate(context.Background(), newClient(), req)
+	assert.True(t, resp.Allowed)
+	assert.NotEmpty(t, resp.Patches)
+
+	obj := m.obj.(*unstructured.Unstructured).Object
+	containers, _, _ := unstructured.NestedSlice(obj, "spec", "template", "spec", "containers")
+	require.Len(t, containers, 1)
+
+	container := containers[0].(map[string]any)
+	assert.Equal(t, "IfNotPresent", container["imagePullPolicy"])
+	assert.Equal(t, "kept", container["unknownField"])
+
+	injected, _, _ := unstructured.NestedString(obj, "spec", "template", "metadata", "annotations", coralv1beta1.ImageSyncInjectedAnnotation)
+	assert.Equal(t, "true", injected)
+
+	_, found, _ := unstructured.NestedMap(obj, "spec", "strategy", "canary")
+	assert.True(t, found)
+}
+
+func TestConfig_TemplatePaths(t *testing.T) {
+	config := &Config{Templates: []TemplateConfig{
+		{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout", Path: "spec.template"},
+		{Group: "serving.knative.dev", Version: "v1", Kind: "Service", Path: "{.spec.template}"},
+	}}
+
+	paths, err := config.TemplatePaths()
+	require.NoError(t, err)
+	assert.Equal(t, TemplatePaths{
+		{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}:   {"spec", "template"},
+		{Group: "serving.knative.dev", Version: "v1", Kind: "Service"}: {"spec", "template"},
+	}, paths)
+
+	for _, path := range []string{"", "spec..template", "spec.containers[0]"} {
+		config := &Config{Templates: []TemplateConfig{{Version: "v1", Kind: "Example", Path: path}}}
+		_, err := config.TemplatePaths()
+		assert.ErrorIs(t, err, ErrInvalidTemplateConfig, path)
+	}
+}
+
+func TestMutator_Mutate_pin_digests(t *testing.T) {
+	const (
+		synced   = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		mirrored = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	)
+
+	tests := []struct {
+		name     string
+		registry string
+		image    string
+		pinned   string
+	}{
+		{
+			name:   "images are pinned to the digest available on the nodes",
+			image:  "docker.io/library/nginx@" + synced,
+			pinned: "web=docker.io/library/nginx:1.27@" + synced,
+		},
+		{
+			name:     "rewritten images are pinned to the digest of the copy",
+			registry: "coral.example.com:5000",
+			image:    "coral.example.com:5000/library/nginx@" + mirrored,
+			pinned:   "web=docker.io/library/nginx:1.27@" + mirrored,
+		},
+	}
+
+	decoder := admission.NewDecoder(scheme.Scheme)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := newDeployment(map[string]string{
+				coralv1beta1.ImageSyncEnableAnnotation:     "true",
+				coralv1beta1.ImageSyncRewriteAnnotation:    "true",
+				coralv1beta1.ImageSyncPinDigestsAnnotation: "true",
+			}, map[string]string{"role": "app"})
+
+			req := newRequest(t, deployment, admissionv1.Create)
+			m, err := FromReq(req, decoder, nil)
+			require.NoError(t, err)
+
+			resp := m.WithRegistry(tt.registry).Mutate(context.Background(), newClient(), req)
+			assert.True(t, resp.Allowed)
+
+			obj := m.obj.(*appsv1.Deployment)
+			spec := obj.Spec.Template.Spec
+			// The busybox digest has not been verified on any node and redis is not synced.
+			assert.Equal(t, "busybox:1.36", spec.InitContainers[0].Image)
+			assert.Equal(t, tt.image, spec.Containers[0].Image)
+			assert.Equal(t, "redis:7", spec.Containers[1].Image)
+
+			assert.Equal(t, tt.pinned, obj.Annotations[coralv1beta1.ImageSyncPinnedDigestsAnnotation])
+			assert.Equal(t, tt.pinned, obj.Spec.Template.Annotations[coralv1beta1.ImageSyncPinnedDigestsAnnotation])
+			assert.Equal(t, "web=docker.io/library/nginx:1.27", obj.Spec.Template.Annotations[coralv1beta1.ImageSyncOriginalImagesAnnotation])
+		})
+	}
+}
+
+func TestMutator_Mutate_generate(t *testing.T) {
+	deployment := newDeployment(map[string]string{
+		coralv1beta1.ImageSyncEnableAnnotation:     "true",
+		coralv1beta1.ImageSyncRewriteAnnotation:    "true",
+		coralv1beta1.ImageSyncPinDigestsAnnotation: "true",
+	}, map[string]string{"role": "app"})
+
+	req := newRequest(t, deployment, admissionv1.Create)
+	m, err := FromReq(req, admission.NewDecoder(scheme.Scheme), nil)
+	require.NoError(t, err)
+
+	resp := m.WithRegistry("coral.example.com:5000").Mutate(context.Background(), newClient(), req)
+	assert.True(t, resp.Allowed)
+
+	obj := m.obj.(*appsv1.Deployment)
+	require.True(t, strings.HasPrefix(obj.Spec.Template.Spec.Containers[0].Image, "coral.example.com:5000/"))
+
+	// The generated imagesync lists the image that the container referenced before it was
+	// rewritten and pinned.
+	assert.Equal(t, []string{"busybox:1.36", "docker.io/library/nginx:1.27", "redis:7"}, workload.Generate(obj).Images)
+}
+
+func TestContainerAnnotation(t *testing.T) {
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init"}},
+		Containers:     []corev1.Container{{Name: "web"}},
+	}
+
+	value := containerAnnotation("web=docker.io/library/nginx:1.26@sha256:aaa,removed=docker.io/library/redis:7@sha256:bbb",
+		map[string]string{"init": "docker.io/library/busybox:1.36@sha256:ccc"}, spec)
+	assert.Equal(t, "init=docker.io/library/busybox:1.36@sha256:ccc,web=docker.io/library/nginx:1.26@sha256:aaa", value)
+
+	value = containerAnnotation(value, map[string]string{"web": "docker.io/library/nginx:1.27@sha256:ddd"}, spec)
+	assert.Equal(t, "init=docker.io/library/busybox:1.36@sha256:ccc,web=docker.io/library/nginx:1.27@sha256:ddd", value)
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injector
+
+import (
+	"fmt"
+
+	coralv1beta1 "ctx.sh/coral/pkg/apis/coral.ctx.sh/v1beta1"
+	"ctx.sh/coral/pkg/store"
+	"ctx.sh/coral/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// prefetchWarnings returns a warning for each of the container images that is not listed in
+// any of the imagesyncs, or that is listed but not available on any node.  Availability is
+// only checked when the node references are known.
+func prefetchWarnings(containers []*corev1.Container, isyncs []coralv1beta1.ImageSyncObject, nodeRef *store.NodeRef) admission.Warnings {
+	listed := make(map[string]bool)
+	for _, isync := range isyncs {
+		for _, img := range isync.GetImages() {
+			if ref, err := util.ParseReference(img); err == nil {
+				listed[ref.String()] = true
+			}
+		}
+	}
+
+	var warnings admission.Warnings
+	for _, image := range images(containers) {
+		switch {
+		case !listed[image]:
+			warnings = append(warnings, fmt.Sprintf("image %s is not prefetched by any imagesync", image))
+		case nodeRef != nil && !nodeRef.IsAvailable(image):
+			warnings = append(warnings, fmt.Sprintf("image %s is not available on any node", image))
+		}
+	}
+
+	return warnings
+}
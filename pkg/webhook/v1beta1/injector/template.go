@@ -0,0 +1,205 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package injector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ErrInvalidTemplateConfig is returned when a template configuration can not be used.
+var ErrInvalidTemplateConfig = errors.New("invalid template configuration")
+
+// TemplatePaths maps the kinds of custom resources to the path of the pod template that they
+// embed.
+type TemplatePaths map[schema.GroupVersionKind][]string
+
+// Config is the injector configuration.
+type Config struct {
+	// Templates are the custom resources that embed a pod template.
+	Templates []TemplateConfig `json:"templates"`
+}
+
+// TemplateConfig maps a custom resource kind to the path of its pod template.
+type TemplateConfig struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Path is the path of the pod template in the object, e.g. spec.template.  The template
+	// must have the metadata and spec fields of a pod template.
+	Path string `json:"path"`
+}
+
+// LoadConfig reads the injector configuration from a YAML or JSON file.
+func LoadConfig(file string) (*Config, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &Config{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(config); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %w", file, err)
+	}
+
+	return config, nil
+}
+
+// TemplatePaths returns the pod template paths of the configured kinds.
+func (c *Config) TemplatePaths() (TemplatePaths, error) {
+	paths := make(TemplatePaths)
+	for _, t := range c.Templates {
+		if t.Version == "" || t.Kind == "" {
+			return nil, fmt.Errorf("%w: version and kind are required", ErrInvalidTemplateConfig)
+		}
+
+		path, err := ParsePath(t.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTemplateConfig, t.Kind, err)
+		}
+
+		paths[schema.GroupVersionKind{Group: t.Group, Version: t.Version, Kind: t.Kind}] = path
+	}
+
+	return paths, nil
+}
+
+// ParsePath splits a dotted field path into its fields.  The JSONPath forms .spec.template
+// and {.spec.template} are accepted as well.
+func ParsePath(path string) ([]string, error) {
+	path = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(path), "{"), "}")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	fields := strings.Split(path, ".")
+	for _, field := range fields {
+		if field == "" || strings.ContainsAny(field, "[]*") {
+			return nil, fmt.Errorf("unsupported path %q", path)
+		}
+	}
+
+	return fields, nil
+}
+
+// unstructuredTemplate is a typed copy of the pod template embedded in an unstructured
+// object.  The mutator works on the typed copy and the changes are written back to the
+// object with apply.
+type unstructuredTemplate struct {
+	obj      *unstructured.Unstructured
+	path     []string
+	original map[string]any
+	template corev1.PodTemplateSpec
+}
+
+// newUnstructuredTemplate returns the pod template at the path of the object, or nil if the
+// object does not have one.
+func newUnstructuredTemplate(obj *unstructured.Unstructured, path []string) (*unstructuredTemplate, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	t := &unstructuredTemplate{
+		obj:  obj,
+		path: path,
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &t.template); err != nil {
+		return nil, fmt.Errorf("invalid pod template at %s: %w", strings.Join(path, "."), err)
+	}
+
+	t.original, err = runtime.DefaultUnstructuredConverter.ToUnstructured(&t.template)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// apply writes the changes made to the typed template back to the object.  Only the fields
+// that changed are written so that the fields a pod template does not know about are kept.
+func (t *unstructuredTemplate) apply() error {
+	updated, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&t.template)
+	if err != nil {
+		return err
+	}
+
+	raw, _, err := unstructured.NestedMap(t.obj.Object, t.path...)
+	if err != nil {
+		return err
+	}
+
+	return unstructured.SetNestedMap(t.obj.Object, applyChanges(raw, t.original, updated), t.path...)
+}
+
+// applyChanges applies the differences between before and after to dst.
+func applyChanges(dst, before, after map[string]any) map[string]any {
+	if dst == nil {
+		dst = make(map[string]any)
+	}
+
+	for key, value := range after {
+		if reflect.DeepEqual(before[key], value) {
+			continue
+		}
+		dst[key] = applyValue(dst[key], before[key], value)
+	}
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			delete(dst, key)
+		}
+	}
+
+	return dst
+}
+
+// applyValue returns the value of dst with the changes between before and after applied.
+// Lists are only merged by index when the length did not change.
+func applyValue(dst, before, after any) any {
+	switch a := after.(type) {
+	case map[string]any:
+		d, dok := dst.(map[string]any)
+		b, bok := before.(map[string]any)
+		if dok && bok {
+			return applyChanges(d, b, a)
+		}
+	case []any:
+		d, dok := dst.([]any)
+		b, bok := before.([]any)
+		if dok && bok && len(d) == len(a) && len(b) == len(a) {
+			for i := range a {
+				if !reflect.DeepEqual(b[i], a[i]) {
+					d[i] = applyValue(d[i], b[i], a[i])
+				}
+			}
+			return d
+		}
+	}
+
+	return after
+}
@@ -104,6 +104,20 @@ func (c *Configuration) WithStorageConfiguration(options *Options) *Configuratio
 	return c
 }
 
+// WithSharedStorageConfiguration wraps the configured storage driver with the shared driver
+// so that the driver created by the registry is handed back to the registry service.
+func (c *Configuration) WithSharedStorageConfiguration(r *Registry) *Configuration {
+	name := c.Storage.Type()
+	c.Storage[sharedDriverName] = configuration.Parameters{
+		"driver":     name,
+		"parameters": c.Storage.Parameters(),
+		"registry":   r,
+	}
+	delete(c.Storage, name)
+
+	return c
+}
+
 // buildS3Configuration creates S3-specific storage configuration with validation.
 func (c *Configuration) WithS3StorageConfiguration(options *Options) configuration.Parameters {
 	// Set sensible defaults for S3 if not provided
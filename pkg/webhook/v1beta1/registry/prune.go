@@ -0,0 +1,217 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/registry/storage"
+	storagedriver "github.com/distribution/distribution/v3/registry/storage/driver"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+
+	"ctx.sh/coral/pkg/util"
+)
+
+// ErrNotRunning is returned when the registry storage is used before the registry service
+// has started.
+var ErrNotRunning = errors.New("registry service is not running")
+
+// Remove removes the image from the registry.  Tagged images are untagged and the manifest
+// is only deleted once no other tag in the repository references it.  The manifests of
+// an index are deleted along with the index unless they are still referenced.  Images that
+// do not exist are ignored.  The blobs are left in place until the next call to Collect.
+func (r *Registry) Remove(ctx context.Context, image string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ns, _, err := r.namespace(ctx)
+	if err != nil {
+		return err
+	}
+
+	ref, err := util.ParseReference(image)
+	if err != nil {
+		return err
+	}
+
+	named, err := reference.WithName(ref.Path())
+	if err != nil {
+		return err
+	}
+
+	repo, err := ns.Repository(ctx, named)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dgst digest.Digest
+	if ref.IsDigested() {
+		dgst = digest.Digest(ref.Digest())
+	} else {
+		tags := repo.Tags(ctx)
+		desc, err := tags.Get(ctx, ref.Tag())
+		if err != nil {
+			if errors.As(err, &distribution.ErrTagUnknown{}) {
+				return nil
+			}
+			return err
+		}
+
+		if err := tags.Untag(ctx, ref.Tag()); err != nil {
+			return err
+		}
+		dgst = desc.Digest
+	}
+
+	referenced, err := referencedManifests(ctx, repo, manifests)
+	if err != nil {
+		return err
+	}
+
+	return deleteManifest(ctx, manifests, dgst, referenced)
+}
+
+// Collect deletes the blobs that are no longer referenced by any manifest.  Manifests
+// that are not tagged are kept so images that are pulled by digest remain available.
+func (r *Registry) Collect(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ns, driver, err := r.namespace(ctx)
+	if err != nil {
+		return err
+	}
+
+	return storage.MarkAndSweep(ctx, driver, ns, storage.GCOpts{
+		RemoveUntagged: false,
+		Quiet:          true,
+	})
+}
+
+// namespace returns a view of the registry storage that allows deletes.
+func (r *Registry) namespace(ctx context.Context) (distribution.Namespace, storagedriver.StorageDriver, error) {
+	if r.driver == nil {
+		return nil, nil, ErrNotRunning
+	}
+
+	ns, err := storage.NewRegistry(ctx, r.driver, storage.EnableDelete)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open the registry storage: %w", err)
+	}
+
+	return ns, r.driver, nil
+}
+
+// referencedManifests returns the digests of the manifests that are referenced by the tags
+// in the repository, including the manifests referenced by an index.
+func referencedManifests(ctx context.Context, repo distribution.Repository, manifests distribution.ManifestService) (map[digest.Digest]bool, error) {
+	referenced := make(map[digest.Digest]bool)
+
+	tags, err := repo.Tags(ctx).All(ctx)
+	if err != nil && !errors.As(err, &distribution.ErrRepositoryUnknown{}) {
+		return nil, err
+	}
+
+	var mark func(dgst digest.Digest) error
+	mark = func(dgst digest.Digest) error {
+		if referenced[dgst] {
+			return nil
+		}
+		referenced[dgst] = true
+
+		children, err := childManifests(ctx, manifests, dgst)
+		if err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			if err := mark(child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, tag := range tags {
+		desc, err := repo.Tags(ctx).Get(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mark(desc.Digest); err != nil {
+			return nil, err
+		}
+	}
+
+	return referenced, nil
+}
+
+// deleteManifest deletes the manifest and the manifests that it references unless they are
+// referenced elsewhere.
+func deleteManifest(ctx context.Context, manifests distribution.ManifestService, dgst digest.Digest, referenced map[digest.Digest]bool) error {
+	if referenced[dgst] {
+		return nil
+	}
+
+	children, err := childManifests(ctx, manifests, dgst)
+	if err != nil {
+		return err
+	}
+
+	if err := manifests.Delete(ctx, dgst); err != nil && !errors.Is(err, distribution.ErrBlobUnknown) {
+		return err
+	}
+
+	for _, child := range children {
+		if err := deleteManifest(ctx, manifests, child, referenced); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// childManifests returns the digests of the manifests referenced by the manifest.  Nothing
+// is returned for manifests that do not exist.
+func childManifests(ctx context.Context, manifests distribution.ManifestService, dgst digest.Digest) ([]digest.Digest, error) {
+	manifest, err := manifests.Get(ctx, dgst)
+	if err != nil {
+		if errors.As(err, &distribution.ErrManifestUnknownRevision{}) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	mediaTypes := distribution.ManifestMediaTypes()
+	children := make([]digest.Digest, 0)
+	for _, desc := range manifest.References() {
+		if slices.Contains(mediaTypes, desc.MediaType) {
+			children = append(children, desc.Digest)
+		}
+	}
+
+	return children, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	"github.com/distribution/distribution/v3/registry/storage/driver/factory"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRegistry(t *testing.T) *Registry {
+	driver, err := factory.Create(context.Background(), "inmemory", nil)
+	require.NoError(t, err)
+
+	reg := New(&Options{})
+	reg.driver = driver
+
+	return reg
+}
+
+func testRepository(t *testing.T, reg *Registry, name string) distribution.Repository {
+	ctx := context.Background()
+
+	ns, _, err := reg.namespace(ctx)
+	require.NoError(t, err)
+
+	named, err := reference.WithName(name)
+	require.NoError(t, err)
+
+	repo, err := ns.Repository(ctx, named)
+	require.NoError(t, err)
+
+	return repo
+}
+
+// putImage stores an image with a single layer and returns the manifest and layer.
+func putImage(t *testing.T, repo distribution.Repository, layer string) (v1.Descriptor, v1.Descriptor) {
+	ctx := context.Background()
+	blobs := repo.Blobs(ctx)
+
+	config, err := blobs.Put(ctx, v1.MediaTypeImageConfig, []byte(`{"layer":"`+layer+`"}`))
+	require.NoError(t, err)
+
+	content, err := blobs.Put(ctx, v1.MediaTypeImageLayer, []byte(layer))
+	require.NoError(t, err)
+
+	m := ocischema.Manifest{
+		MediaType: v1.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []v1.Descriptor{content},
+	}
+	m.SchemaVersion = 2
+
+	deserialized, err := ocischema.FromStruct(m)
+	require.NoError(t, err)
+
+	manifests, err := repo.Manifests(ctx)
+	require.NoError(t, err)
+
+	dgst, err := manifests.Put(ctx, deserialized)
+	require.NoError(t, err)
+
+	return v1.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: dgst}, content
+}
+
+// putIndex stores an index referencing the manifests and returns its digest.
+func putIndex(t *testing.T, repo distribution.Repository, descriptors ...v1.Descriptor) digest.Digest {
+	ctx := context.Background()
+
+	index, err := ocischema.FromDescriptors(descriptors, nil)
+	require.NoError(t, err)
+
+	manifests, err := repo.Manifests(ctx)
+	require.NoError(t, err)
+
+	dgst, err := manifests.Put(ctx, index)
+	require.NoError(t, err)
+
+	return dgst
+}
+
+func tag(t *testing.T, repo distribution.Repository, dgst digest.Digest, tags ...string) {
+	ctx := context.Background()
+	for _, tag := range tags {
+		require.NoError(t, repo.Tags(ctx).Tag(ctx, tag, v1.Descriptor{Digest: dgst}))
+	}
+}
+
+func manifestExists(t *testing.T, repo distribution.Repository, dgst digest.Digest) bool {
+	ctx := context.Background()
+
+	manifests, err := repo.Manifests(ctx)
+	require.NoError(t, err)
+
+	exists, err := manifests.Exists(ctx, dgst)
+	require.NoError(t, err)
+
+	return exists
+}
+
+func blobExists(t *testing.T, reg *Registry, dgst digest.Digest) bool {
+	ctx := context.Background()
+
+	ns, _, err := reg.namespace(ctx)
+	require.NoError(t, err)
+
+	_, err = ns.BlobStatter().Stat(ctx, dgst)
+	if err == distribution.ErrBlobUnknown {
+		return false
+	}
+	require.NoError(t, err)
+
+	return true
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	ctx := context.Background()
+	reg := testRegistry(t)
+
+	nginx := testRepository(t, reg, "library/nginx")
+	latest, latestLayer := putImage(t, nginx, "latest")
+	previous, previousLayer := putImage(t, nginx, "previous")
+	tag(t, nginx, latest.Digest, "1.27", "latest")
+	tag(t, nginx, previous.Digest, "1.26")
+
+	// The manifest is kept while another tag references it.
+	require.NoError(t, reg.Remove(ctx, "localhost:5000/library/nginx:1.27"))
+	_, err := nginx.Tags(ctx).Get(ctx, "1.27")
+	assert.ErrorAs(t, err, &distribution.ErrTagUnknown{})
+	assert.True(t, manifestExists(t, nginx, latest.Digest))
+
+	require.NoError(t, reg.Remove(ctx, "localhost:5000/library/nginx:latest"))
+	assert.False(t, manifestExists(t, nginx, latest.Digest))
+	assert.True(t, manifestExists(t, nginx, previous.Digest))
+
+	// Images that have already been removed are ignored.
+	assert.NoError(t, reg.Remove(ctx, "localhost:5000/library/nginx:latest"))
+
+	// The blobs are only deleted by the garbage collection.
+	assert.True(t, blobExists(t, reg, latestLayer.Digest))
+	require.NoError(t, reg.Collect(ctx))
+	assert.False(t, blobExists(t, reg, latestLayer.Digest))
+	assert.True(t, blobExists(t, reg, previousLayer.Digest))
+}
+
+func TestRegistry_Remove_index(t *testing.T) {
+	ctx := context.Background()
+	reg := testRegistry(t)
+
+	redis := testRepository(t, reg, "library/redis")
+	amd64, amd64Layer := putImage(t, redis, "amd64")
+	arm64, _ := putImage(t, redis, "arm64")
+	index := putIndex(t, redis, amd64, arm64)
+	tag(t, redis, index, "7")
+	tag(t, redis, amd64.Digest, "7-amd64")
+
+	// Manifests of the index that are still tagged are kept.
+	require.NoError(t, reg.Remove(ctx, "localhost:5000/library/redis:7"))
+	assert.False(t, manifestExists(t, redis, index))
+	assert.False(t, manifestExists(t, redis, arm64.Digest))
+	assert.True(t, manifestExists(t, redis, amd64.Digest))
+
+	// Digests are kept while they are tagged.
+	require.NoError(t, reg.Remove(ctx, "localhost:5000/library/redis@"+amd64.Digest.String()))
+	assert.True(t, manifestExists(t, redis, amd64.Digest))
+
+	require.NoError(t, reg.Remove(ctx, "localhost:5000/library/redis:7-amd64"))
+	assert.False(t, manifestExists(t, redis, amd64.Digest))
+
+	require.NoError(t, reg.Collect(ctx))
+	assert.False(t, blobExists(t, reg, amd64Layer.Digest))
+}
+
+func TestRegistry_Remove_notRunning(t *testing.T) {
+	reg := New(&Options{})
+
+	assert.ErrorIs(t, reg.Remove(context.Background(), "localhost:5000/library/nginx:latest"), ErrNotRunning)
+	assert.ErrorIs(t, reg.Collect(context.Background()), ErrNotRunning)
+}
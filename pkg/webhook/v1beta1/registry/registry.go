@@ -25,6 +25,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/distribution/distribution/v3/registry"
+	storagedriver "github.com/distribution/distribution/v3/registry/storage/driver"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
 	_ "github.com/distribution/distribution/v3/registry/storage/driver/s3-aws"
 	"github.com/go-logr/logr"
@@ -37,14 +38,18 @@ import (
 type Registry struct {
 	Options  *Options
 	registry *registry.Registry
+	driver   storagedriver.StorageDriver
 	mu       sync.RWMutex
 }
 
-func SetupWebhookWithManager(ctx context.Context, mgr ctrl.Manager, opts *Options) error {
-	reg := &Registry{
+// New creates a new registry service.
+func New(opts *Options) *Registry {
+	return &Registry{
 		Options: opts,
 	}
+}
 
+func SetupWebhookWithManager(ctx context.Context, mgr ctrl.Manager, reg *Registry) error {
 	return mgr.Add(reg)
 }
 
@@ -62,7 +67,7 @@ func (r *Registry) Start(ctx context.Context) error {
 	// Apply defaults to any unset options
 	r.Options.setDefaults()
 
-	config := NewConfiguration(r.Options)
+	config := NewConfiguration(r.Options).WithSharedStorageConfiguration(r)
 
 	log.Info("starting registry service")
 
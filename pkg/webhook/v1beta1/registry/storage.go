@@ -0,0 +1,67 @@
+// Copyright 2025 Coral Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/distribution/distribution/v3/configuration"
+	storagedriver "github.com/distribution/distribution/v3/registry/storage/driver"
+	"github.com/distribution/distribution/v3/registry/storage/driver/factory"
+)
+
+// sharedDriverName is the name of the storage driver that wraps the configured storage
+// driver so that the driver created by the registry can also be used for maintenance.  The
+// in-memory driver in particular cannot be recreated without losing its contents.
+const sharedDriverName = "coral"
+
+func init() {
+	factory.Register(sharedDriverName, &sharedDriverFactory{})
+}
+
+// sharedDriverFactory creates the configured storage driver and hands it to the registry
+// service that requested it.
+type sharedDriverFactory struct{}
+
+// Create creates the underlying storage driver.  The parameters contain the name and
+// parameters of the underlying driver along with the registry service.
+func (f *sharedDriverFactory) Create(ctx context.Context, parameters map[string]any) (storagedriver.StorageDriver, error) {
+	reg, ok := parameters["registry"].(*Registry)
+	if !ok {
+		return nil, fmt.Errorf("%s storage driver: registry parameter is required", sharedDriverName)
+	}
+
+	name, _ := parameters["driver"].(string)
+	params := make(configuration.Parameters)
+	if p, ok := parameters["parameters"].(configuration.Parameters); ok {
+		maps.Copy(params, p)
+	}
+
+	if ua, ok := parameters["useragent"]; ok {
+		params["useragent"] = ua
+	}
+
+	driver, err := factory.Create(ctx, name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The registry service lock is held while the registry is created.
+	reg.driver = driver
+
+	return driver, nil
+}
@@ -29,15 +29,19 @@ import (
 )
 
 type Options struct {
-	RegistryPort int
-	NodeRef      *store.NodeRef
+	Registry       *registry.Registry
+	RegistryHost   string
+	InjectorConfig string
+	NodeRef        *store.NodeRef
 }
 
-// +kubebuilder:webhook:verbs=create;update,path=/mutate-coral-ctx-sh-v1beta1-imagesync,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=coral.ctx.sh,resources=imagesyncs,versions=v1,name=mimagesync.coral.ctx.sh,admissionReviewVersions=v1beta1,sideEffects=none
-// +kubebuilder:webhook:verbs=create;update,path=/validate-coral-ctx-sh-v1beta1-imagesync,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=coral.ctx.sh,resources=imagesyncs,versions=v1,name=vimagesync.coral.ctx.sh,admissionReviewVersions=v1beta1,sideEffects=none
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-coral-ctx-sh-v1beta1-imagesync,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=coral.ctx.sh,resources=imagesyncs,versions=v1beta1,name=mimagesync.coral.ctx.sh,admissionReviewVersions=v1beta1,sideEffects=none
+// +kubebuilder:webhook:verbs=create;update,path=/validate-coral-ctx-sh-v1beta1-imagesync,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=coral.ctx.sh,resources=imagesyncs,versions=v1beta1,name=vimagesync.coral.ctx.sh,admissionReviewVersions=v1beta1,sideEffects=none
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-coral-ctx-sh-v1beta1-clusterimagesync,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=coral.ctx.sh,resources=clusterimagesyncs,versions=v1beta1,name=mclusterimagesync.coral.ctx.sh,admissionReviewVersions=v1beta1,sideEffects=none
+// +kubebuilder:webhook:verbs=create;update,path=/validate-coral-ctx-sh-v1beta1-clusterimagesync,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=coral.ctx.sh,resources=clusterimagesyncs,versions=v1beta1,name=vclusterimagesync.coral.ctx.sh,admissionReviewVersions=v1beta1,sideEffects=none
 
-// TODO: implement the injector for volume mounts.
-// webhook:verbs=create;update,path=/inject-coral-ctx-sh-v1beta1-imagesync,mutating=true,failurePolicy=ignore,matchPolicy=Equivalent,groups=apps;batch,resources=cronjobs;daemonsets;deployments;jobs;replicasets;replicationcontrollers;statefulsets,versions=v1,name=minjector.coral.ctx.sh,admissionReviewVersions=v1beta1,sideEffects=none
+// +kubebuilder:webhook:verbs=create;update,path=/inject-coral-ctx-sh-v1beta1-imagesync,mutating=true,failurePolicy=ignore,matchPolicy=Equivalent,groups="";apps;batch,resources=cronjobs;daemonsets;deployments;jobs;replicasets;replicationcontrollers;statefulsets,versions=v1,name=minjector.coral.ctx.sh,admissionReviewVersions=v1,sideEffects=none
+// +kubebuilder:webhook:verbs=create,path=/inject-coral-ctx-sh-v1beta1-imagesync,mutating=true,failurePolicy=ignore,matchPolicy=Equivalent,groups="",resources=pods,versions=v1,name=mpodinjector.coral.ctx.sh,admissionReviewVersions=v1,sideEffects=none
 
 func SetupWebhooksWithManager(ctx context.Context, mgr manager.Manager, opts *Options) error {
 	// Setup check endpoints for health and readiness
@@ -56,7 +60,28 @@ func SetupWebhooksWithManager(ctx context.Context, mgr manager.Manager, opts *Op
 		return err
 	}
 
-	if err := injector.SetupWebhookWithManager(mgr); err != nil {
+	cw := imagesync.ClusterWebhook{}
+	if err := cw.SetupWebhookWithManager(mgr); err != nil {
+		return fmt.Errorf("could not set up clusterimagesync webhook: %v", err)
+	}
+
+	templates := make(injector.TemplatePaths)
+	if opts.InjectorConfig != "" {
+		config, err := injector.LoadConfig(opts.InjectorConfig)
+		if err != nil {
+			return fmt.Errorf("could not load the injector config: %v", err)
+		}
+
+		if templates, err = config.TemplatePaths(); err != nil {
+			return fmt.Errorf("could not load the injector config: %v", err)
+		}
+	}
+
+	if err := injector.SetupWebhookWithManager(mgr, &injector.Options{
+		Registry:  opts.RegistryHost,
+		NodeRef:   opts.NodeRef,
+		Templates: templates,
+	}); err != nil {
 		return fmt.Errorf("could not set up injector webhook: %v", err)
 	}
 
@@ -68,9 +93,7 @@ func SetupWebhooksWithManager(ctx context.Context, mgr manager.Manager, opts *Op
 	}
 
 	// Register the registry service
-	if err := registry.SetupWebhookWithManager(ctx, mgr, &registry.Options{
-		Port: opts.RegistryPort,
-	}); err != nil {
+	if err := registry.SetupWebhookWithManager(ctx, mgr, opts.Registry); err != nil {
 		return fmt.Errorf("could not set up registry webhook: %v", err)
 	}
 